@@ -8,14 +8,27 @@ import (
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 
+	"github.com/conradoqg/statuspage-exporter/internal/alertmanager"
 	"github.com/conradoqg/statuspage-exporter/internal/collector"
 	"github.com/conradoqg/statuspage-exporter/internal/config"
+	"github.com/conradoqg/statuspage-exporter/internal/events"
+	"github.com/conradoqg/statuspage-exporter/internal/journal"
 	"github.com/conradoqg/statuspage-exporter/internal/logx"
+	"github.com/conradoqg/statuspage-exporter/internal/mirror"
+	"github.com/conradoqg/statuspage-exporter/internal/notifier"
+	"github.com/conradoqg/statuspage-exporter/internal/providers"
+	"github.com/conradoqg/statuspage-exporter/internal/server"
+	"github.com/conradoqg/statuspage-exporter/internal/version"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "rules" {
+		runRules(os.Args[2:])
+		return
+	}
+
 	var (
 		configPath    string
 		listenAddress string
@@ -42,23 +55,61 @@ func main() {
 	}
 	logx.SetLevelFromString(cfg.Common.LogLevel)
 	logx.Infof("log level set to %s", cfg.Common.LogLevel)
+	logx.Infof("statuspage-exporter version=%s commit=%s", version.Version, version.Commit)
 
 	reg := prometheus.NewRegistry()
+	// Exporter self-instrumentation: runtime, process, build info and outbound HTTP
+	selfNamespace := cfg.Common.MetricPrefix + "_exporter"
+	providers.SetMetricsNamespace(selfNamespace)
+	reg.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		version.NewCollector(selfNamespace),
+	)
+	reg.MustRegister(providers.HTTPCollectors()...)
+
+	jr, err := journal.Open(cfg.Journal)
+	if err != nil {
+		log.Printf("failed to open journal: %v", err)
+		os.Exit(1)
+	}
+	bus := events.NewBus(cfg.Server.EventBuffer)
+	bus.Resume(jr.LastID())
+	jr.Run(bus)
+	reg.MustRegister(prometheus.NewCounterFunc(prometheus.CounterOpts{
+		Namespace: selfNamespace,
+		Name:      "events_dropped_total",
+		Help:      "Change events not delivered to a slow subscriber (event stream clients, Alertmanager and incident mirror triggers)",
+	}, func() float64 { return float64(bus.Dropped()) }))
 
-	coll, err := collector.New(cfg)
+	coll, err := collector.New(cfg, bus)
 	if err != nil {
 		log.Printf("failed to init collector: %v", err)
 		os.Exit(1)
 	}
 	reg.MustRegister(coll)
 
-	mux := http.NewServeMux()
-	mux.Handle("/metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
-	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) })
+	notify, err := notifier.New(cfg, selfNamespace)
+	if err != nil {
+		log.Printf("failed to init notifiers: %v", err)
+		os.Exit(1)
+	}
+	reg.MustRegister(notify.Collector())
+	notify.Run(coll.Events())
+
+	if am := alertmanager.New(cfg, selfNamespace); am != nil {
+		reg.MustRegister(am.Collectors()...)
+		am.Run(coll)
+	}
+	if mir := mirror.New(cfg, selfNamespace); mir != nil {
+		reg.MustRegister(mir.Collector())
+		mir.Restore(jr)
+		mir.Run(coll)
+	}
 
 	srv := &http.Server{
 		Addr:         cfg.Server.Listen,
-		Handler:      mux,
+		Handler:      server.NewMux(reg, coll, jr),
 		ReadTimeout:  10 * time.Second,
 		WriteTimeout: 10 * time.Second,
 		IdleTimeout:  60 * time.Second,
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"github.com/conradoqg/statuspage-exporter/internal/config"
+	"github.com/conradoqg/statuspage-exporter/internal/rules"
+)
+
+// runRules implements `statuspage-exporter rules`: it prints the Prometheus
+// rules for the configured pages instead of starting the exporter.
+func runRules(args []string) {
+	fs := flag.NewFlagSet("rules", flag.ExitOnError)
+	var configPath, format, output string
+	fs.StringVar(&configPath, "config", "config.yaml", "Path to config YAML")
+	fs.StringVar(&format, "format", rules.FormatRules, "Output format: rules (Prometheus rules file) or crd (PrometheusRule resource)")
+	fs.StringVar(&output, "output", "", "Write to this file instead of stdout")
+	fs.Parse(args)
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		log.Printf("failed to load config from %s: %v", configPath, err)
+		os.Exit(1)
+	}
+	b, err := rules.Render(cfg, format)
+	if err != nil {
+		log.Printf("failed to render rules: %v", err)
+		os.Exit(1)
+	}
+	if output == "" {
+		_, err = os.Stdout.Write(b)
+	} else {
+		err = os.WriteFile(output, b, 0o644)
+	}
+	if err != nil {
+		log.Printf("failed to write rules: %v", err)
+		os.Exit(1)
+	}
+}
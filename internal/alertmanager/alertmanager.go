@@ -0,0 +1,269 @@
+// Package alertmanager pushes non-operational components and open vendor
+// incidents to Alertmanager's /api/v2/alerts endpoint.
+package alertmanager
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/conradoqg/statuspage-exporter/internal/collector"
+	"github.com/conradoqg/statuspage-exporter/internal/config"
+	"github.com/conradoqg/statuspage-exporter/internal/logx"
+	"github.com/conradoqg/statuspage-exporter/internal/outbound"
+	"github.com/conradoqg/statuspage-exporter/internal/providers"
+)
+
+// Alert names
+const (
+	alertComponent = "VendorComponentNotOperational"
+	alertIncident  = "VendorIncidentOpen"
+)
+
+// Alert is the Alertmanager v2 postable alert.
+type Alert struct {
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"`
+	GeneratorURL string            `json:"generatorURL,omitempty"`
+}
+
+type Client struct {
+	urls        []string
+	resend      time.Duration
+	headers     map[string]string
+	userAgent   string
+	labels      map[string]string
+	minSeverity providers.NormalizedStatus
+	client      *http.Client
+
+	// Alerts sent on the previous cycle, by label fingerprint
+	active map[string]alertState
+
+	posts  *prometheus.CounterVec
+	firing prometheus.Gauge
+}
+
+type alertState struct {
+	Alert
+	page string
+}
+
+// New returns nil when no Alertmanager URL is configured.
+func New(cfg *config.Config, namespace string) *Client {
+	am := cfg.Alertmanager
+	if len(am.URLs) == 0 {
+		return nil
+	}
+	c := &Client{
+		urls:      am.URLs,
+		resend:    am.ResendInterval,
+		headers:   am.Headers,
+		userAgent: cfg.Common.UserAgent,
+		labels:    am.Labels,
+		client:    &http.Client{Timeout: am.Timeout},
+		active:    make(map[string]alertState),
+		posts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "alertmanager_posts_total",
+			Help:      "Alert batches posted to Alertmanager by target URL and outcome (sent, failed)",
+		}, []string{"alertmanager", "outcome"}),
+		firing: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "alertmanager_firing_alerts",
+			Help:      "Alerts currently pushed to Alertmanager as firing",
+		}),
+	}
+	if am.MinSeverity != "" {
+		c.minSeverity, _ = providers.ParseStatus(am.MinSeverity)
+	}
+	return c
+}
+
+// Collectors exposes the client's self metrics.
+func (c *Client) Collectors() []prometheus.Collector {
+	return []prometheus.Collector{c.posts, c.firing}
+}
+
+// Run pushes alerts every resend interval, and as soon as the exporter
+// detects a change.
+func (c *Client) Run(exp *collector.Exporter) {
+	logx.Infof("alertmanager: pushing alerts to %s every %s", strings.Join(c.urls, ", "), c.resend)
+	_, changes, _ := exp.Events().Subscribe(0)
+	go func() {
+		<-exp.Ready()
+		ticker := time.NewTicker(c.resend)
+		defer ticker.Stop()
+		for {
+			c.push(exp.Snapshot(), time.Now())
+			select {
+			case <-ticker.C:
+			case <-changes:
+				// Coalesce the burst of events from one refresh
+				time.Sleep(time.Second)
+				for len(changes) > 0 {
+					<-changes
+				}
+			}
+		}
+	}()
+}
+
+// push sends every firing alert, plus a resolution for alerts that are gone.
+func (c *Client) push(pages []collector.PageState, now time.Time) {
+	next := make(map[string]alertState)
+	failing := make(map[string]bool)
+	for _, ps := range pages {
+		if ps.Err != nil || ps.FetchedAt.IsZero() {
+			// Keep the page's alerts as they were rather than resolving them
+			failing[ps.Page] = true
+			continue
+		}
+		for _, a := range c.pageAlerts(ps) {
+			fp := fingerprint(a.Labels)
+			if a.StartsAt.IsZero() {
+				a.StartsAt = now
+				if prev, ok := c.active[fp]; ok {
+					a.StartsAt = prev.StartsAt
+				}
+			}
+			next[fp] = alertState{Alert: a, page: ps.Page}
+		}
+	}
+	for fp, prev := range c.active {
+		if failing[prev.page] {
+			next[fp] = prev
+		}
+	}
+
+	// Firing alerts expire on their own if the exporter stops resending them
+	expiry := now.Add(4 * c.resend)
+	batch := make([]Alert, 0, len(next)+len(c.active))
+	for _, st := range next {
+		a := st.Alert
+		a.EndsAt = expiry
+		batch = append(batch, a)
+	}
+	for fp, prev := range c.active {
+		if _, ok := next[fp]; !ok {
+			a := prev.Alert
+			a.EndsAt = now
+			batch = append(batch, a)
+		}
+	}
+	c.active = next
+	c.firing.Set(float64(len(next)))
+	if len(batch) == 0 {
+		return
+	}
+	body, err := json.Marshal(batch)
+	if err != nil {
+		logx.Errorf("alertmanager: encode alerts: %v", err)
+		return
+	}
+	for _, u := range c.urls {
+		if err := outbound.Post(c.client, strings.TrimSuffix(u, "/")+"/api/v2/alerts", c.userAgent, c.headers, body); err != nil {
+			c.posts.WithLabelValues(u, "failed").Inc()
+			logx.Warnf("alertmanager: post to %s: %v", u, err)
+			continue
+		}
+		c.posts.WithLabelValues(u, "sent").Inc()
+		logx.Debugf("alertmanager: posted %d alerts to %s", len(batch), u)
+	}
+}
+
+// pageAlerts builds the firing alerts of one page.
+func (c *Client) pageAlerts(ps collector.PageState) []Alert {
+	var out []Alert
+	for _, comp := range ps.Components {
+		if comp.Up || comp.Status < c.minSeverity {
+			continue
+		}
+		labels := c.baseLabels(ps, alertComponent, comp.Status)
+		labels["component"] = comp.Name
+		outbound.SetIf(labels, "group", comp.Group)
+		outbound.SetIf(labels, "region", comp.Region)
+		labels["status"] = comp.Status.String()
+		ann := map[string]string{
+			"summary": fmt.Sprintf("%s / %s is %s", ps.Page, comp.Name, strings.ReplaceAll(comp.Status.String(), "_", " ")),
+		}
+		outbound.SetIf(ann, "description", comp.Description)
+		if comp.RawStatus != "" {
+			ann["raw_status"] = comp.RawStatus
+		}
+		out = append(out, Alert{
+			Labels:       labels,
+			Annotations:  ann,
+			StartsAt:     comp.Since,
+			GeneratorURL: ps.URL,
+		})
+	}
+	for _, inc := range ps.Incidents {
+		if inc.Severity < c.minSeverity {
+			continue
+		}
+		labels := c.baseLabels(ps, alertIncident, inc.Severity)
+		labels["incident_id"] = collector.IncidentKey(inc.ID, inc.Name)
+		ann := map[string]string{
+			"summary": fmt.Sprintf("%s incident: %s", ps.Page, inc.Name),
+		}
+		outbound.SetIf(ann, "incident_status", inc.Status)
+		outbound.SetIf(ann, "incident_impact", inc.Impact)
+		outbound.SetIf(ann, "incident_url", inc.URL)
+		a := Alert{Labels: labels, Annotations: ann, StartsAt: inc.StartedAt, GeneratorURL: ps.URL}
+		if inc.URL != "" {
+			a.GeneratorURL = inc.URL
+		}
+		out = append(out, a)
+	}
+	return out
+}
+
+// baseLabels merges static, page and identifying labels; later ones win.
+func (c *Client) baseLabels(ps collector.PageState, name string, st providers.NormalizedStatus) map[string]string {
+	labels := make(map[string]string, len(c.labels)+len(ps.Labels)+8)
+	for k, v := range c.labels {
+		labels[k] = v
+	}
+	for k, v := range ps.Labels {
+		labels[k] = v
+	}
+	labels["alertname"] = name
+	labels["provider"] = ps.Provider
+	labels["page"] = ps.Page
+	labels["severity"] = severity(st)
+	return labels
+}
+
+// severity maps a normalized status to the conventional severity label.
+func severity(st providers.NormalizedStatus) string {
+	switch st {
+	case providers.StatusMajorOutage:
+		return "critical"
+	case providers.StatusPartialOutage, providers.StatusDegraded:
+		return "warning"
+	}
+	return "info"
+}
+
+func fingerprint(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for _, k := range keys {
+		b.WriteString(k)
+		b.WriteByte(0)
+		b.WriteString(labels[k])
+		b.WriteByte(0)
+	}
+	return b.String()
+}
@@ -0,0 +1,175 @@
+package alertmanager
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/conradoqg/statuspage-exporter/internal/collector"
+	"github.com/conradoqg/statuspage-exporter/internal/config"
+	"github.com/conradoqg/statuspage-exporter/internal/providers"
+)
+
+// fakeAlertmanager records each posted batch as "alertname/subject severity
+// startsAt→endsAt" lines, times relative to base.
+type fakeAlertmanager struct {
+	mu      sync.Mutex
+	base    time.Time
+	batches [][]string
+}
+
+func (f *fakeAlertmanager) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/api/v2/alerts" {
+		http.NotFound(w, r)
+		return
+	}
+	var alerts []Alert
+	if err := json.NewDecoder(r.Body).Decode(&alerts); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var batch []string
+	for _, a := range alerts {
+		subject := a.Labels["component"] + a.Labels["incident_id"]
+		batch = append(batch, fmt.Sprintf("%s/%s %s %v→%v", a.Labels["alertname"], subject, a.Labels["severity"],
+			a.StartsAt.Sub(f.base), a.EndsAt.Sub(f.base)))
+	}
+	sort.Strings(batch)
+	f.mu.Lock()
+	f.batches = append(f.batches, batch)
+	f.mu.Unlock()
+}
+
+func (f *fakeAlertmanager) take() [][]string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	out := f.batches
+	f.batches = nil
+	return out
+}
+
+func TestPush(t *testing.T) {
+	base := time.Now().Truncate(time.Second)
+	am := &fakeAlertmanager{base: base}
+	srv := httptest.NewServer(am)
+	defer srv.Close()
+	down := httptest.NewServer(http.NotFoundHandler())
+	defer down.Close()
+
+	c := New(&config.Config{Alertmanager: config.Alertmanager{
+		URLs:           []string{srv.URL + "/", down.URL},
+		ResendInterval: time.Minute,
+		Timeout:        time.Second,
+		MinSeverity:    "partial_outage",
+		Labels:         map[string]string{"source": "vendors"},
+	}}, "test")
+
+	healthy := collector.PageState{Provider: "statuspage", Page: "github", FetchedAt: base}
+	broken := healthy
+	broken.Components = []collector.ComponentState{
+		{Component: providers.Component{Name: "Actions", Status: providers.StatusPartialOutage}},
+		{Component: providers.Component{Name: "Pages", Status: providers.StatusMajorOutage}, Since: base.Add(-time.Hour)},
+		{Component: providers.Component{Name: "Wiki", Status: providers.StatusDegraded}},
+		{Component: providers.Component{Name: "Git", Status: providers.StatusUnknown}, Up: true},
+	}
+	broken.Incidents = []providers.Incident{
+		{ID: "inc1", Name: "Actions delayed", Severity: providers.StatusMajorOutage, StartedAt: base.Add(-30 * time.Minute)},
+		{ID: "inc2", Name: "Wiki slow", Severity: providers.StatusDegraded},
+	}
+	failing := healthy
+	failing.Err = errors.New("fetch failed")
+
+	steps := []struct {
+		name   string
+		page   collector.PageState
+		at     time.Duration
+		want   [][]string
+		firing float64
+	}{
+		{"alerts fire", broken, 0, [][]string{{
+			"VendorComponentNotOperational/Actions warning 0s→4m0s",
+			"VendorComponentNotOperational/Pages critical -1h0m0s→4m0s",
+			"VendorIncidentOpen/inc1 critical -30m0s→4m0s",
+		}}, 3},
+		{"start times are kept", broken, time.Minute, [][]string{{
+			"VendorComponentNotOperational/Actions warning 0s→5m0s",
+			"VendorComponentNotOperational/Pages critical -1h0m0s→5m0s",
+			"VendorIncidentOpen/inc1 critical -30m0s→5m0s",
+		}}, 3},
+		{"failing pages keep their alerts", failing, 2 * time.Minute, [][]string{{
+			"VendorComponentNotOperational/Actions warning 0s→6m0s",
+			"VendorComponentNotOperational/Pages critical -1h0m0s→6m0s",
+			"VendorIncidentOpen/inc1 critical -30m0s→6m0s",
+		}}, 3},
+		{"gone alerts are resolved", healthy, 3 * time.Minute, [][]string{{
+			"VendorComponentNotOperational/Actions warning 0s→3m0s",
+			"VendorComponentNotOperational/Pages critical -1h0m0s→3m0s",
+			"VendorIncidentOpen/inc1 critical -30m0s→3m0s",
+		}}, 0},
+		{"nothing to send", healthy, 4 * time.Minute, nil, 0},
+	}
+	for _, s := range steps {
+		c.push([]collector.PageState{s.page}, base.Add(s.at))
+		if got := am.take(); !reflect.DeepEqual(got, s.want) {
+			t.Errorf("%s: got %q, want %q", s.name, got, s.want)
+		}
+		var m dto.Metric
+		if err := c.firing.Write(&m); err != nil {
+			t.Fatal(err)
+		}
+		if got := m.GetGauge().GetValue(); got != s.firing {
+			t.Errorf("%s: firing = %v, want %v", s.name, got, s.firing)
+		}
+	}
+
+	for u, want := range map[string]float64{srv.URL + "/": 4, down.URL: 0} {
+		var m dto.Metric
+		if err := c.posts.WithLabelValues(u, "sent").Write(&m); err != nil {
+			t.Fatal(err)
+		}
+		if got := m.GetCounter().GetValue(); got != want {
+			t.Errorf("%s: sent = %v, want %v", u, got, want)
+		}
+	}
+}
+
+func TestAlertLabels(t *testing.T) {
+	c := New(&config.Config{Alertmanager: config.Alertmanager{
+		URLs:   []string{"http://alertmanager:9093"},
+		Labels: map[string]string{"source": "vendors", "team": "platform"},
+	}}, "test")
+	ps := collector.PageState{
+		Provider: "aws", Page: "aws", URL: "https://health.aws.amazon.com",
+		Labels: map[string]string{"team": "infra"},
+		Components: []collector.ComponentState{{Component: providers.Component{
+			Name: "EC2", Group: "Compute", Region: "us-east-1", Status: providers.StatusDegraded, RawStatus: "Increased error rates", Description: "Instances",
+		}}},
+	}
+	alerts := c.pageAlerts(ps)
+	if len(alerts) != 1 {
+		t.Fatalf("got %d alerts", len(alerts))
+	}
+	wantLabels := map[string]string{
+		"alertname": "VendorComponentNotOperational", "source": "vendors", "team": "infra",
+		"provider": "aws", "page": "aws", "severity": "warning", "status": "degraded_performance",
+		"component": "EC2", "group": "Compute", "region": "us-east-1",
+	}
+	if !reflect.DeepEqual(alerts[0].Labels, wantLabels) {
+		t.Errorf("labels %v, want %v", alerts[0].Labels, wantLabels)
+	}
+	wantAnn := map[string]string{
+		"summary": "aws / EC2 is degraded performance", "description": "Instances", "raw_status": "Increased error rates",
+	}
+	if !reflect.DeepEqual(alerts[0].Annotations, wantAnn) || alerts[0].GeneratorURL != ps.URL {
+		t.Errorf("annotations %v, generatorURL %q", alerts[0].Annotations, alerts[0].GeneratorURL)
+	}
+}
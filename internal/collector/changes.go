@@ -0,0 +1,209 @@
+package collector
+
+import (
+	"sort"
+	"time"
+
+	"github.com/conradoqg/statuspage-exporter/internal/events"
+	"github.com/conradoqg/statuspage-exporter/internal/providers"
+)
+
+// componentSince is a component's effective status and when it was entered.
+type componentSince struct {
+	Status providers.NormalizedStatus
+	Since  time.Time
+	// Identifying labels, to report the component once it is gone
+	Name, Group, Region string
+}
+
+// openIncident is an open vendor incident and when the exporter first saw it.
+type openIncident struct {
+	providers.Incident
+	Seen time.Time
+}
+
+// Events returns the bus on which detected changes are published.
+func (e *Exporter) Events() *events.Bus {
+	return e.bus
+}
+
+// Ready is closed once every page has completed its first fetch, so
+// consumers of Snapshot can tell a missing page from one not fetched yet.
+func (e *Exporter) Ready() <-chan struct{} {
+	return e.ready
+}
+
+// detectChanges diffs a fetch outcome against the state kept in ce and
+// updates it. The first successful fetch only establishes the baseline.
+// Components that appear or disappear afterwards (per-product feeds,
+// incident-derived components, unknown_policy omit) are treated as
+// operational while absent. The caller must hold ce.mu.
+func (e *Exporter) detectChanges(i int, ce *cacheEntry, res providers.Result, err error, now time.Time) []events.Event {
+	var out []events.Event
+	if err != nil {
+		if ce.failingSince.IsZero() {
+			ce.failingSince = now
+			ev := e.newEvent(i, events.PageFetchFailed, now)
+			ev.Error = err.Error()
+			out = append(out, ev)
+		}
+		return out
+	}
+	if !ce.failingSince.IsZero() {
+		ev := e.newEvent(i, events.PageFetchRecovered, now)
+		ev.DurationSeconds = now.Sub(ce.failingSince).Seconds()
+		out = append(out, ev)
+		ce.failingSince = time.Time{}
+	}
+
+	states := make(map[string]componentSince, len(res.Components))
+	for _, c := range e.effectiveComponents(i, res, ce.lastKnown) {
+		key := componentKey(c.Component)
+		prev, ok := ce.states[key]
+		cur := componentSince{Status: c.Status, Since: now, Name: c.Name, Group: c.Group, Region: c.Region}
+		switch {
+		case ok && prev.Status == c.Status:
+			states[key] = prev
+			continue
+		case !ce.baselined && !c.UpdatedAt.IsZero() && c.UpdatedAt.Before(now):
+			cur.Since = c.UpdatedAt
+		}
+		states[key] = cur
+		if !ce.baselined {
+			continue
+		}
+		if !ok {
+			if c.Status == providers.StatusOperational {
+				continue
+			}
+			prev = componentSince{Status: providers.StatusOperational, Since: now}
+		}
+		out = append(out, e.componentEvent(i, prev, cur, now))
+	}
+	if ce.baselined {
+		keys := make([]string, 0, len(ce.states))
+		for key, prev := range ce.states {
+			if _, ok := states[key]; !ok && prev.Status != providers.StatusOperational {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			prev := ce.states[key]
+			cur := prev
+			cur.Status, cur.Since = providers.StatusOperational, now
+			out = append(out, e.componentEvent(i, prev, cur, now))
+		}
+	}
+	ce.states = states
+
+	incidents := make(map[string]openIncident, len(res.Incidents))
+	for _, inc := range res.Incidents {
+		key := IncidentKey(inc.ID, inc.Name)
+		if prev, ok := ce.incidents[key]; ok {
+			incidents[key] = openIncident{Incident: inc, Seen: prev.Seen}
+			continue
+		}
+		incidents[key] = openIncident{Incident: inc, Seen: now}
+		if ce.baselined {
+			ev := e.newEvent(i, events.IncidentOpened, now)
+			ev.Severity = inc.Severity
+			ev.Incident = events.NewIncident(inc)
+			out = append(out, ev)
+		}
+	}
+	if ce.baselined {
+		keys := make([]string, 0, len(ce.incidents))
+		for key := range ce.incidents {
+			if _, ok := incidents[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			prev := ce.incidents[key]
+			ev := e.newEvent(i, events.IncidentResolved, now)
+			ev.Severity = prev.Severity
+			ev.Incident = events.NewIncident(prev.Incident)
+			ev.Incident.Status = "resolved"
+			started := prev.StartedAt
+			if started.IsZero() || started.After(prev.Seen) {
+				started = prev.Seen
+			}
+			ev.DurationSeconds = now.Sub(started).Seconds()
+			out = append(out, ev)
+		}
+	}
+	ce.incidents = incidents
+
+	// Maintenances are recorded when announced and again when they leave the
+	// page, so their final window is kept even for ones announced before start.
+	maintenances := make(map[string]providers.Incident, len(res.Maintenances))
+	for _, m := range res.Maintenances {
+		key := IncidentKey(m.ID, m.Name)
+		maintenances[key] = m
+		if _, ok := ce.maintenances[key]; !ok && ce.baselined {
+			out = append(out, e.maintenanceEvent(i, events.MaintenanceScheduled, m, now))
+		}
+	}
+	keys := make([]string, 0, len(ce.maintenances))
+	for key := range ce.maintenances {
+		if _, ok := maintenances[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		out = append(out, e.maintenanceEvent(i, events.MaintenanceCompleted, ce.maintenances[key], now))
+	}
+	ce.maintenances = maintenances
+	if !ce.baselined {
+		ce.baselined, ce.baselinedAt = true, now
+	}
+	return out
+}
+
+func (e *Exporter) newEvent(i int, typ events.Type, now time.Time) events.Event {
+	m := e.metas[i]
+	return events.Event{
+		Type:     typ,
+		Time:     now,
+		Provider: m.Provider,
+		Page:     m.Page,
+		URL:      m.URL,
+		Labels:   e.pageLabels(i),
+	}
+}
+
+func (e *Exporter) componentEvent(i int, prev, cur componentSince, now time.Time) events.Event {
+	ev := e.newEvent(i, events.ComponentStatusChanged, now)
+	ev.Component, ev.Group, ev.Region = cur.Name, cur.Group, cur.Region
+	ev.From, ev.To = prev.Status.String(), cur.Status.String()
+	ev.Severity = Worst(prev.Status, cur.Status)
+	ev.DurationSeconds = now.Sub(prev.Since).Seconds()
+	return ev
+}
+
+func (e *Exporter) maintenanceEvent(i int, typ events.Type, m providers.Incident, now time.Time) events.Event {
+	ev := e.newEvent(i, typ, now)
+	ev.Severity = m.Severity
+	ev.Incident = events.NewIncident(m)
+	if typ == events.MaintenanceCompleted {
+		ev.Incident.Status = "completed"
+		// Maintenances leave the page when completed, early or late; one
+		// withdrawn before its start keeps the scheduled window
+		if !m.StartedAt.After(now) {
+			ev.Incident.EndsAt = now
+		}
+	}
+	return ev
+}
+
+// IncidentKey identifies a vendor incident or maintenance within its page:
+// the vendor ID, else the name.
+func IncidentKey(id, name string) string {
+	if id != "" {
+		return id
+	}
+	return name
+}
@@ -0,0 +1,213 @@
+package collector
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/conradoqg/statuspage-exporter/internal/config"
+	"github.com/conradoqg/statuspage-exporter/internal/events"
+	"github.com/conradoqg/statuspage-exporter/internal/providers"
+)
+
+func TestDetectChanges(t *testing.T) {
+	t0 := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	api := func(st providers.NormalizedStatus) providers.Component {
+		return providers.Component{Name: "API", Group: "Core", Status: st}
+	}
+	outage := providers.Incident{ID: "inc1", Name: "API errors", Severity: providers.StatusMajorOutage, StartedAt: t0.Add(-time.Hour)}
+	upgrade := providers.Incident{ID: "m1", Name: "Database upgrade", Severity: providers.StatusUnderMaintenance, StartedAt: t0.Add(time.Hour), EndsAt: t0.Add(2 * time.Hour)}
+
+	type step struct {
+		res  providers.Result
+		err  error
+		want []events.Type
+	}
+	tests := []struct {
+		name   string
+		policy string
+		steps  []step
+		check  func(t *testing.T, evs []events.Event)
+	}{
+		{
+			name: "first fetch is the baseline",
+			steps: []step{
+				{res: providers.Result{Components: []providers.Component{api(providers.StatusDegraded)}, Incidents: []providers.Incident{outage}, Maintenances: []providers.Incident{upgrade}}},
+			},
+		},
+		{
+			name: "component status change",
+			steps: []step{
+				{res: providers.Result{Components: []providers.Component{api(providers.StatusOperational)}}},
+				{res: providers.Result{Components: []providers.Component{api(providers.StatusOperational)}}},
+				{res: providers.Result{Components: []providers.Component{api(providers.StatusPartialOutage)}}, want: []events.Type{events.ComponentStatusChanged}},
+				{res: providers.Result{Components: []providers.Component{api(providers.StatusOperational)}}, want: []events.Type{events.ComponentStatusChanged}},
+			},
+			check: func(t *testing.T, evs []events.Event) {
+				ev := evs[0]
+				if ev.Component != "API" || ev.Group != "Core" || ev.From != "operational" || ev.To != "partial_outage" {
+					t.Errorf("unexpected event %+v", ev)
+				}
+				if ev.Severity != providers.StatusPartialOutage || ev.DurationSeconds != 2*60 {
+					t.Errorf("severity %s, duration %v", ev.Severity, ev.DurationSeconds)
+				}
+				if evs[1].Severity != providers.StatusPartialOutage || evs[1].DurationSeconds != 60 {
+					t.Errorf("recovery severity %s, duration %v", evs[1].Severity, evs[1].DurationSeconds)
+				}
+			},
+		},
+		{
+			name:   "changes are detected on effective statuses",
+			policy: config.UnknownLastKnown,
+			steps: []step{
+				{res: providers.Result{Components: []providers.Component{api(providers.StatusDegraded)}}},
+				{res: providers.Result{Components: []providers.Component{api(providers.StatusUnknown)}}},
+				{res: providers.Result{Components: []providers.Component{api(providers.StatusOperational)}}, want: []events.Type{events.ComponentStatusChanged}},
+			},
+		},
+		{
+			name: "component appears and disappears after the baseline",
+			steps: []step{
+				{res: providers.Result{}},
+				{res: providers.Result{Components: []providers.Component{api(providers.StatusMajorOutage)}}, want: []events.Type{events.ComponentStatusChanged}},
+				{res: providers.Result{}, want: []events.Type{events.ComponentStatusChanged}},
+			},
+			check: func(t *testing.T, evs []events.Event) {
+				if evs[0].Component != "API" || evs[0].From != "operational" || evs[0].To != "major_outage" || evs[0].DurationSeconds != 0 {
+					t.Errorf("unexpected appear event %+v", evs[0])
+				}
+				if evs[1].Component != "API" || evs[1].Group != "Core" || evs[1].From != "major_outage" || evs[1].To != "operational" || evs[1].DurationSeconds != 60 {
+					t.Errorf("unexpected disappear event %+v", evs[1])
+				}
+				if evs[1].Severity != providers.StatusMajorOutage {
+					t.Errorf("severity %s", evs[1].Severity)
+				}
+			},
+		},
+		{
+			name: "operational components appear and disappear silently",
+			steps: []step{
+				{res: providers.Result{}},
+				{res: providers.Result{Components: []providers.Component{api(providers.StatusOperational)}}},
+				{res: providers.Result{}},
+			},
+		},
+		{
+			name:   "omitted unknown components",
+			policy: config.UnknownOmit,
+			steps: []step{
+				{res: providers.Result{Components: []providers.Component{api(providers.StatusDegraded)}}},
+				{res: providers.Result{Components: []providers.Component{api(providers.StatusUnknown)}}, want: []events.Type{events.ComponentStatusChanged}},
+				{res: providers.Result{Components: []providers.Component{api(providers.StatusDegraded)}}, want: []events.Type{events.ComponentStatusChanged}},
+			},
+			check: func(t *testing.T, evs []events.Event) {
+				if evs[0].To != "operational" || evs[1].From != "operational" || evs[1].To != "degraded_performance" {
+					t.Errorf("unexpected events %+v", evs)
+				}
+			},
+		},
+		{
+			name: "incident opened and resolved",
+			steps: []step{
+				{res: providers.Result{}},
+				{res: providers.Result{Incidents: []providers.Incident{outage}}, want: []events.Type{events.IncidentOpened}},
+				{res: providers.Result{Incidents: []providers.Incident{outage}}},
+				{res: providers.Result{}, want: []events.Type{events.IncidentResolved}},
+			},
+			check: func(t *testing.T, evs []events.Event) {
+				if evs[0].Incident == nil || evs[0].Incident.ID != "inc1" || evs[0].Severity != providers.StatusMajorOutage {
+					t.Errorf("unexpected open event %+v", evs[0])
+				}
+				// Counted from the vendor's start, an hour before it was first seen
+				if evs[1].Incident.Status != "resolved" || evs[1].DurationSeconds != 63*60 {
+					t.Errorf("unexpected resolve event %+v", evs[1])
+				}
+			},
+		},
+		{
+			name: "fetch failure and recovery",
+			steps: []step{
+				{res: providers.Result{Components: []providers.Component{api(providers.StatusOperational)}}},
+				{err: errors.New("boom"), want: []events.Type{events.PageFetchFailed}},
+				{err: errors.New("boom")},
+				{res: providers.Result{Components: []providers.Component{api(providers.StatusOperational)}}, want: []events.Type{events.PageFetchRecovered}},
+			},
+			check: func(t *testing.T, evs []events.Event) {
+				if evs[0].Error != "boom" || evs[1].DurationSeconds != 2*60 {
+					t.Errorf("unexpected events %+v", evs)
+				}
+			},
+		},
+		{
+			name: "failure before the baseline",
+			steps: []step{
+				{err: errors.New("boom"), want: []events.Type{events.PageFetchFailed}},
+				{res: providers.Result{Incidents: []providers.Incident{outage}}, want: []events.Type{events.PageFetchRecovered}},
+			},
+		},
+		{
+			name: "maintenance scheduled and withdrawn",
+			steps: []step{
+				{res: providers.Result{}},
+				{res: providers.Result{Maintenances: []providers.Incident{upgrade}}, want: []events.Type{events.MaintenanceScheduled}},
+				{res: providers.Result{}, want: []events.Type{events.MaintenanceCompleted}},
+			},
+			check: func(t *testing.T, evs []events.Event) {
+				if evs[1].Incident.Status != "completed" || !evs[1].Incident.EndsAt.Equal(upgrade.EndsAt) {
+					t.Errorf("unexpected completion %+v", evs[1].Incident)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := tt.policy
+			if policy == "" {
+				policy = config.UnknownDown
+			}
+			e := newTestExporter(pageMeta{Provider: "statuspage", Page: "p", URL: "https://status.example.com", UnknownPolicy: policy})
+			ce := e.caches[0]
+			var all []events.Event
+			for n, s := range tt.steps {
+				now := t0.Add(time.Duration(n) * time.Minute)
+				if s.err == nil {
+					ce.lastKnown = rememberKnown(ce.lastKnown, s.res.Components)
+				}
+				evs := e.detectChanges(0, ce, s.res, s.err, now)
+				var got []events.Type
+				for _, ev := range evs {
+					got = append(got, ev.Type)
+					if ev.Page != "p" || ev.Provider != "statuspage" || !ev.Time.Equal(now) {
+						t.Errorf("step %d: unexpected event header %+v", n, ev)
+					}
+				}
+				if !reflect.DeepEqual(got, s.want) {
+					t.Fatalf("step %d: got %v, want %v", n, got, s.want)
+				}
+				all = append(all, evs...)
+			}
+			if tt.check != nil {
+				tt.check(t, all)
+			}
+		})
+	}
+}
+
+func TestDetectChangesBaselineSince(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	e := newTestExporter(pageMeta{Provider: "statuspage", Page: "p", UnknownPolicy: config.UnknownDown})
+	ce := e.caches[0]
+	res := providers.Result{Components: []providers.Component{
+		{Name: "old", Status: providers.StatusDegraded, UpdatedAt: now.Add(-time.Hour)},
+		{Name: "future", Status: providers.StatusDegraded, UpdatedAt: now.Add(time.Hour)},
+		{Name: "undated", Status: providers.StatusDegraded},
+	}}
+	e.detectChanges(0, ce, res, nil, now)
+	want := map[string]time.Time{"old||": now.Add(-time.Hour), "future||": now, "undated||": now}
+	for key, since := range want {
+		if got := ce.states[key].Since; !got.Equal(since) {
+			t.Errorf("%s: since %v, want %v", key, got, since)
+		}
+	}
+}
@@ -3,28 +3,72 @@ package collector
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/conradoqg/statuspage-exporter/internal/config"
+	"github.com/conradoqg/statuspage-exporter/internal/events"
 	"github.com/conradoqg/statuspage-exporter/internal/logx"
 	"github.com/conradoqg/statuspage-exporter/internal/providers"
 )
 
 type Exporter struct {
-	providers   []providers.Provider
-	caches      []*cacheEntry
-	metas       []pageMeta
-	unknownIsUp bool
+	providers []providers.Provider
+	caches    []*cacheEntry
+	metas     []pageMeta
+	// Extra static label names (common + page labels), appended to every series
+	labelKeys []string
+	// Internal services mapped to vendor components
+	deps []dependency
+	// Region spelling -> canonical region/continent
+	regions map[string]regionInfo
+	// Metric name prefix and status -> value mapping for status code metrics
+	prefix      string
+	statusCodes map[providers.NormalizedStatus]float64
+	// Which component status representations to emit (see config.StatusMetric*)
+	statusCodeOn bool
+	stateSet     bool
+	// Health score penalties by status and per open incident
+	penalties       map[providers.NormalizedStatus]float64
+	incidentPenalty float64
+	// Component limit across all pages; 0 means unlimited
+	totalLimit int
 
 	up         *prometheus.Desc
 	statusCode *prometheus.Desc
+	state      *prometheus.Desc
 	scrapeDur  *prometheus.Desc
 	scrapeOK   *prometheus.Desc
 	incidents  *prometheus.Desc
 	pageInfo   *prometheus.Desc
+	compInfo   *prometheus.Desc
+	compUpdate *prometheus.Desc
+	pageUpdate *prometheus.Desc
+	depStatus  *prometheus.Desc
+	depInfo    *prometheus.Desc
+
+	pageHealth  *prometheus.Desc
+	groupHealth *prometheus.Desc
+	depHealth   *prometheus.Desc
+
+	// Counters updated on refresh, collected alongside the const metrics
+	counters     []*prometheus.CounterVec
+	unmapped     *prometheus.CounterVec
+	unmappedMu   sync.Mutex
+	unmappedSeen map[string]struct{}
+	filtered     *prometheus.CounterVec
+	dropped      *prometheus.CounterVec
+
+	// Changes detected between refreshes
+	bus *events.Bus
+	// Closed once every page has completed its first fetch
+	ready   chan struct{}
+	pending sync.WaitGroup
 }
 
 type cacheEntry struct {
@@ -33,182 +77,404 @@ type cacheEntry struct {
 	err     error
 	dur     float64
 	updated time.Time
+	// Last definite status per component key, replaced (never mutated) on refresh
+	lastKnown map[string]providers.NormalizedStatus
+	// Set once the component limit warning has been logged
+	limitWarned atomic.Bool
+	// Components kept by the last successful fetch, counted against the
+	// global limit
+	kept atomic.Int64
+	// Change detection state, see detectChanges
+	baselined    bool
+	baselinedAt  time.Time
+	states       map[string]componentSince
+	incidents    map[string]openIncident
+	maintenances map[string]providers.Incident
+	failingSince time.Time
+	firstFetch   sync.Once
 }
 
 type pageMeta struct {
 	Provider string
 	Page     string
 	URL      string
+	// Unknown-status policy resolved from page and common config
+	UnknownPolicy string
+	// Values for Exporter.labelKeys, in the same order
+	LabelValues []string
+	// Component filters; a component is kept when it matches any include
+	// (or there are none) and no exclude
+	Include []matcher
+	Exclude []matcher
+	// Relabel rules applied after filtering
+	Relabel []relabelRule
+	// Health score weights, first match wins
+	Weights []weightRule
+	// Component limit after filtering and relabeling; 0 means unlimited
+	MaxComponents int
 }
 
-func New(cfg *config.Config) (*Exporter, error) {
+// New starts refreshing every configured page; detected changes are
+// published on bus.
+func New(cfg *config.Config, bus *events.Bus) (*Exporter, error) {
 	ps, metas, err := buildProviders(cfg)
 	if err != nil {
 		return nil, err
 	}
 	e := &Exporter{
-		providers:   ps,
-		caches:      make([]*cacheEntry, len(ps)),
-		metas:       metas,
-		unknownIsUp: cfg.Common.UnknownIsUp,
-		up: prometheus.NewDesc(
-			"statuspage_component_up",
-			"Component operational status (1=up, 0=not)",
-			[]string{"provider", "page", "component", "group", "region"}, nil,
-		),
-		statusCode: prometheus.NewDesc(
-			"statuspage_component_status_code",
-			"Component normalized status code (0=unknown,1=operational,2=maintenance,3=degraded,4=partial_outage,5=major_outage)",
-			[]string{"provider", "page", "component", "group", "region", "status"}, nil,
-		),
-		scrapeDur: prometheus.NewDesc(
-			"statuspage_scrape_duration_seconds",
-			"Scrape duration by provider/page",
-			[]string{"provider", "page"}, nil,
-		),
-		scrapeOK: prometheus.NewDesc(
-			"statuspage_scrape_success",
-			"Scrape success (1=ok)",
-			[]string{"provider", "page"}, nil,
-		),
-		incidents: prometheus.NewDesc(
-			"statuspage_open_incidents",
-			"Open incidents reported by provider/page (when available)",
-			[]string{"provider", "page"}, nil,
-		),
-		pageInfo: prometheus.NewDesc(
-			"statuspage_page_info",
-			"Static page info metric for dashboards; value is 1",
-			[]string{"provider", "page", "url"}, nil,
-		),
+		providers:       ps,
+		caches:          make([]*cacheEntry, len(ps)),
+		metas:           metas,
+		labelKeys:       extraLabelKeys(cfg),
+		regions:         buildRegions(cfg.Regions),
+		penalties:       buildPenalties(cfg.Common.Health.Penalties),
+		prefix:          cfg.Common.MetricPrefix,
+		statusCodes:     buildStatusCodes(cfg.Common.StatusCodes),
+		statusCodeOn:    cfg.Common.StatusMetric != config.StatusMetricStateSet,
+		stateSet:        cfg.Common.StatusMetric != config.StatusMetricCode,
+		unmappedSeen:    make(map[string]struct{}),
+		incidentPenalty: cfg.Common.Health.IncidentPenalty,
+		totalLimit:      cfg.Common.MaxTotalComponents,
+		bus:             bus,
+		ready:           make(chan struct{}),
+	}
+	for i := range e.metas {
+		e.metas[i].LabelValues = e.labelValues(cfg, i)
 	}
+	if e.deps, err = compileDependencies(cfg.Dependencies); err != nil {
+		return nil, err
+	}
+	e.up = e.newDesc(
+		"component_up",
+		"Component operational status (1=up, 0=not)",
+		"provider", "page", "component", "group", "region", "canonical_region", "continent",
+	)
+	e.statusCode = e.newDesc(
+		"component_status_code",
+		"Component normalized status code ("+e.codeHelp()+")",
+		"provider", "page", "component", "group", "region", "canonical_region", "continent", "status",
+	)
+	e.state = e.newDesc(
+		"component_state",
+		"Component status as a state set: one series per possible status, 1 for the current one and 0 otherwise",
+		"provider", "page", "component", "group", "region", "canonical_region", "continent", "state",
+	)
+	e.scrapeDur = e.newDesc(
+		"scrape_duration_seconds",
+		"Scrape duration by provider/page",
+		"provider", "page",
+	)
+	e.scrapeOK = e.newDesc(
+		"scrape_success",
+		"Scrape success (1=ok)",
+		"provider", "page",
+	)
+	e.incidents = e.newDesc(
+		"open_incidents",
+		"Open incidents reported by provider/page (when available)",
+		"provider", "page",
+	)
+	e.pageInfo = e.newDesc(
+		"page_info",
+		"Static page info metric for dashboards; value is 1",
+		"provider", "page", "url",
+	)
+	e.compInfo = e.newDesc(
+		"component_info",
+		"Vendor metadata per component (id, raw status); value is 1",
+		"provider", "page", "component", "group", "region", "id", "raw_status",
+	)
+	e.compUpdate = e.newDesc(
+		"component_last_vendor_update_timestamp_seconds",
+		"Vendor-reported last update time of the component (unix seconds, when available)",
+		"provider", "page", "component", "group", "region",
+	)
+	e.pageUpdate = e.newDesc(
+		"page_last_vendor_update_timestamp_seconds",
+		"Vendor-reported last update time of the status page (unix seconds); falls back to the latest component update",
+		"provider", "page",
+	)
+	e.dropped = e.newCounterVec(
+		"components_dropped_total",
+		"Components dropped because the page exceeded max_components",
+		"provider", "page",
+	)
+	e.depStatus = prometheus.NewDesc(
+		e.prefix+"_service_dependency_status_code",
+		"Worst normalized status code across the vendor components an internal service depends on (same codes as component_status_code)",
+		[]string{"service"}, nil,
+	)
+	e.depInfo = prometheus.NewDesc(
+		e.prefix+"_service_dependency_info",
+		"Vendor components currently mapped to an internal service; value is 1",
+		[]string{"service", "provider", "page", "component", "group", "region"}, nil,
+	)
+	e.pageHealth = e.newDesc(
+		"page_health_score",
+		"Weighted 0-100 health score of the page (100=all operational)",
+		"provider", "page",
+	)
+	e.groupHealth = e.newDesc(
+		"group_health_score",
+		"Weighted 0-100 health score of a component group",
+		"provider", "page", "group",
+	)
+	e.depHealth = prometheus.NewDesc(
+		e.prefix+"_service_dependency_health_score",
+		"Weighted 0-100 health score across the vendor components an internal service depends on",
+		[]string{"service"}, nil,
+	)
+	e.unmapped = e.newCounterVec(
+		"unmapped_status_total",
+		"Component status values returned by the vendor that could not be normalized",
+		"provider", "page", "raw_status",
+	)
+	e.filtered = e.newCounterVec(
+		"components_filtered_total",
+		"Components removed by the page include/exclude filters",
+		"provider", "page",
+	)
 
+	e.pending.Add(len(e.providers))
+	go func() {
+		e.pending.Wait()
+		close(e.ready)
+	}()
 	// Start background refresh loops respecting provider intervals
 	for i, p := range e.providers {
-		ce := &cacheEntry{}
-		e.caches[i] = ce
+		e.caches[i] = &cacheEntry{}
 		logx.Infof("starting provider loop: provider=%T page=%d", p, i)
-		go e.refreshLoop(p, ce)
+		go e.refreshLoop(i)
 	}
 	return e, nil
 }
 
 func (e *Exporter) Describe(ch chan<- *prometheus.Desc) {
 	ch <- e.up
-	ch <- e.statusCode
+	if e.statusCodeOn {
+		ch <- e.statusCode
+	}
+	if e.stateSet {
+		ch <- e.state
+	}
 	ch <- e.scrapeDur
 	ch <- e.scrapeOK
 	ch <- e.incidents
 	ch <- e.pageInfo
+	ch <- e.compInfo
+	ch <- e.compUpdate
+	ch <- e.pageUpdate
+	ch <- e.depStatus
+	ch <- e.depInfo
+	ch <- e.pageHealth
+	ch <- e.groupHealth
+	ch <- e.depHealth
+	for _, c := range e.counters {
+		c.Describe(ch)
+	}
 }
 
 func (e *Exporter) Collect(ch chan<- prometheus.Metric) {
+	e.collect(ch, nil)
+}
+
+// collect emits metrics for the pages accepted by keep (all pages when nil).
+func (e *Exporter) collect(ch chan<- prometheus.Metric, keep func(i int) bool) {
 	// Emit static page info for each configured target
-	for _, m := range e.metas {
-		ch <- prometheus.MustNewConstMetric(e.pageInfo, prometheus.GaugeValue, 1, m.Provider, m.Page, m.URL)
+	for i, m := range e.metas {
+		if keep == nil || keep(i) {
+			e.emit(ch, i, e.pageInfo, 1, m.Provider, m.Page, m.URL)
+		}
 	}
 	for i := range e.providers {
-		e.collectFromCache(i, ch)
+		if keep == nil || keep(i) {
+			e.collectFromCache(i, ch)
+		}
+	}
+	if keep == nil {
+		e.collectDependencies(ch)
 	}
+	e.collectCounters(ch, keep)
 }
 
-func (e *Exporter) collectFromCache(i int, ch chan<- prometheus.Metric) {
+// cached returns the latest cached state of page i.
+func (e *Exporter) cached(i int) (res providers.Result, dur float64, lastKnown map[string]providers.NormalizedStatus, err error) {
 	ce := e.caches[i]
 	ce.mu.RLock()
-	res := ce.res
-	err := ce.err
-	dur := ce.dur
+	res, err, dur, lastKnown = ce.res, ce.err, ce.dur, ce.lastKnown
 	ce.mu.RUnlock()
 
 	// If cache is empty (first run), do a synchronous fetch to avoid empty metrics
 	if res.Provider == "" && err == nil {
-		p := e.providers[i]
-		start := time.Now()
-		ctx, cancel := context.WithTimeout(context.Background(), p.Timeout())
-		r, er := p.Fetch(ctx)
-		cancel()
-		dur = time.Since(start).Seconds()
-		ce.mu.Lock()
-		ce.res, ce.err, ce.dur, ce.updated = r, er, dur, time.Now()
-		res, err = r, er
-		ce.mu.Unlock()
+		e.refresh(i)
+		ce.mu.RLock()
+		res, err, dur, lastKnown = ce.res, ce.err, ce.dur, ce.lastKnown
+		ce.mu.RUnlock()
 	}
+	return res, dur, lastKnown, err
+}
+
+func (e *Exporter) collectFromCache(i int, ch chan<- prometheus.Metric) {
+	res, dur, lastKnown, err := e.cached(i)
 
 	if res.Provider == "" {
 		// nothing to expose yet
 		return
 	}
 
-	ch <- prometheus.MustNewConstMetric(e.scrapeDur, prometheus.GaugeValue, dur, res.Provider, res.Page)
+	e.emit(ch, i, e.scrapeDur, dur, res.Provider, res.Page)
 	if err != nil {
-		ch <- prometheus.MustNewConstMetric(e.scrapeOK, prometheus.GaugeValue, 0, res.Provider, res.Page)
+		e.emit(ch, i, e.scrapeOK, 0, res.Provider, res.Page)
 		return
 	}
-	ch <- prometheus.MustNewConstMetric(e.scrapeOK, prometheus.GaugeValue, 1, res.Provider, res.Page)
+	e.emit(ch, i, e.scrapeOK, 1, res.Provider, res.Page)
 	if res.OpenIncidents >= 0 {
-		ch <- prometheus.MustNewConstMetric(e.incidents, prometheus.GaugeValue, float64(res.OpenIncidents), res.Provider, res.Page)
+		e.emit(ch, i, e.incidents, float64(res.OpenIncidents), res.Provider, res.Page)
+	}
+	if ts := pageUpdatedAt(res); !ts.IsZero() {
+		e.emit(ch, i, e.pageUpdate, float64(ts.Unix()), res.Provider, res.Page)
 	}
 	// Deduplicate by labelset to avoid duplicate series if a provider returns repeated items
 	seenUp := make(map[string]struct{})
 	seenStatus := make(map[string]struct{})
+	policy := e.metas[i].UnknownPolicy
 	for _, c := range res.Components {
+		var up float64
+		var emit bool
+		c.Status, up, emit = applyUnknownPolicy(policy, c.Status, lastKnown[componentKey(c)])
+		if !emit {
+			continue
+		}
 		keyUp := fmt.Sprintf("%s|%s|%s|%s|%s", res.Provider, res.Page, c.Name, c.Group, c.Region)
+		canonical, continent := e.normalizeRegion(c.Region)
 		if _, ok := seenUp[keyUp]; !ok {
-			up := 0.0
-			if c.Status == providers.StatusOperational || (c.Status == providers.StatusUnknown && e.unknownIsUp) {
-				up = 1.0
+			e.emit(ch, i, e.up, up, res.Provider, res.Page, c.Name, c.Group, c.Region, canonical, continent)
+			e.emit(ch, i, e.compInfo, 1, res.Provider, res.Page, c.Name, c.Group, c.Region, c.ID, c.RawStatus)
+			if !c.UpdatedAt.IsZero() {
+				e.emit(ch, i, e.compUpdate, float64(c.UpdatedAt.Unix()), res.Provider, res.Page, c.Name, c.Group, c.Region)
+			}
+			if e.stateSet {
+				// One series per possible status so series stay stable across transitions
+				for st := providers.StatusUnknown; st <= providers.StatusMajorOutage; st++ {
+					v := 0.0
+					if st == c.Status {
+						v = 1
+					}
+					e.emit(ch, i, e.state, v, res.Provider, res.Page, c.Name, c.Group, c.Region, canonical, continent, st.String())
+				}
 			}
-			ch <- prometheus.MustNewConstMetric(e.up, prometheus.GaugeValue, up, res.Provider, res.Page, c.Name, c.Group, c.Region)
 			seenUp[keyUp] = struct{}{}
 		}
+		if !e.statusCodeOn {
+			continue
+		}
 		keyStatus := keyUp + "|" + c.Status.String()
 		if _, ok := seenStatus[keyStatus]; !ok {
-			ch <- prometheus.MustNewConstMetric(e.statusCode, prometheus.GaugeValue, float64(mapCode(c.Status)), res.Provider, res.Page, c.Name, c.Group, c.Region, c.Status.String())
+			e.emit(ch, i, e.statusCode, e.code(c.Status), res.Provider, res.Page, c.Name, c.Group, c.Region, canonical, continent, c.Status.String())
 			seenStatus[keyStatus] = struct{}{}
 		}
 	}
+	e.collectHealth(ch, i, res, lastKnown)
 }
 
-func (e *Exporter) refreshLoop(p providers.Provider, ce *cacheEntry) {
+func (e *Exporter) refreshLoop(i int) {
 	// initial immediate fetch
 	for {
-		start := time.Now()
-		ctx, cancel := context.WithTimeout(context.Background(), p.Timeout())
-		logx.Debugf("fetching from provider interval=%s timeout=%s", p.Interval(), p.Timeout())
-		res, err := p.Fetch(ctx)
-		cancel()
-		ce.mu.Lock()
-		ce.res = res
-		ce.err = err
-		ce.dur = time.Since(start).Seconds()
-		ce.updated = time.Now()
-		ce.mu.Unlock()
-		if err != nil {
-			logx.Warnf("fetch error provider=%s page=%s err=%v", res.Provider, res.Page, err)
-		} else {
-			logx.Debugf("fetched provider=%s page=%s components=%d incidents=%d dur=%.3fs", res.Provider, res.Page, len(res.Components), res.OpenIncidents, ce.dur)
+		e.refresh(i)
+		time.Sleep(e.providers[i].Interval())
+	}
+}
+
+// refresh fetches a single page and stores the outcome in its cache entry.
+func (e *Exporter) refresh(i int) {
+	p := e.providers[i]
+	ce := e.caches[i]
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), p.Timeout())
+	logx.Debugf("fetching from provider interval=%s timeout=%s", p.Interval(), p.Timeout())
+	res, err := p.Fetch(ctx)
+	cancel()
+	dur := time.Since(start).Seconds()
+	if err == nil {
+		res = e.process(i, res)
+		e.trackUnmapped(i, res)
+	}
+	ce.mu.Lock()
+	ce.res = res
+	ce.err = err
+	ce.dur = dur
+	ce.updated = time.Now()
+	if err == nil {
+		ce.lastKnown = rememberKnown(ce.lastKnown, res.Components)
+	}
+	changes := e.detectChanges(i, ce, res, err, ce.updated)
+	ce.mu.Unlock()
+	for _, ev := range changes {
+		e.bus.Publish(ev)
+	}
+	ce.firstFetch.Do(e.pending.Done)
+	if err != nil {
+		logx.Warnf("fetch error provider=%s page=%s err=%v", res.Provider, res.Page, err)
+	} else {
+		logx.Debugf("fetched provider=%s page=%s components=%d incidents=%d dur=%.3fs", res.Provider, res.Page, len(res.Components), res.OpenIncidents, dur)
+	}
+}
+
+// trackUnmapped counts vendor status values we could not normalize and logs
+// each distinct value once, so schema drift is not hidden by unknown_is_up.
+func (e *Exporter) trackUnmapped(i int, res providers.Result) {
+	for _, c := range res.Components {
+		if !c.Unmapped() {
+			continue
+		}
+		e.counter(e.unmapped, i, res.Provider, res.Page, c.RawStatus).Inc()
+		key := res.Provider + "|" + res.Page + "|" + c.RawStatus
+		e.unmappedMu.Lock()
+		_, seen := e.unmappedSeen[key]
+		e.unmappedSeen[key] = struct{}{}
+		e.unmappedMu.Unlock()
+		if !seen {
+			logx.Warnf("unmapped vendor status provider=%s page=%s component=%q raw_status=%q", res.Provider, res.Page, c.Name, c.RawStatus)
 		}
-		time.Sleep(p.Interval())
 	}
 }
 
-func mapCode(s providers.NormalizedStatus) int {
-	switch s {
-	case providers.StatusUnknown:
-		return 0
-	case providers.StatusOperational:
-		return 1
-	case providers.StatusUnderMaintenance:
-		return 2
-	case providers.StatusDegraded:
-		return 3
-	case providers.StatusPartialOutage:
-		return 4
-	case providers.StatusMajorOutage:
-		return 5
-	default:
-		return 0
+// pageUpdatedAt returns the page-level vendor timestamp, or the most recent
+// component update when the vendor does not report one for the page.
+func pageUpdatedAt(res providers.Result) time.Time {
+	ts := res.UpdatedAt
+	if ts.IsZero() {
+		for _, c := range res.Components {
+			if c.UpdatedAt.After(ts) {
+				ts = c.UpdatedAt
+			}
+		}
+	}
+	return ts
+}
+
+// code returns the configured numeric value of a status for the status code metrics.
+func (e *Exporter) code(s providers.NormalizedStatus) float64 {
+	return e.statusCodes[s]
+}
+
+// codeHelp describes the configured status code scheme, e.g. "0=unknown,1=operational,...".
+func (e *Exporter) codeHelp() string {
+	parts := make([]string, 0, len(e.statusCodes))
+	for st := providers.StatusUnknown; st <= providers.StatusMajorOutage; st++ {
+		parts = append(parts, strconv.FormatFloat(e.statusCodes[st], 'g', -1, 64)+"="+st.String())
+	}
+	return strings.Join(parts, ",")
+}
+
+func buildStatusCodes(cfg map[string]float64) map[providers.NormalizedStatus]float64 {
+	out := make(map[providers.NormalizedStatus]float64, len(cfg))
+	for k, v := range cfg {
+		if st, ok := providers.ParseStatus(k); ok {
+			out[st] = v
+		}
 	}
+	return out
 }
 
 func buildProviders(cfg *config.Config) ([]providers.Provider, []pageMeta, error) {
@@ -227,38 +493,64 @@ func buildProviders(cfg *config.Config) ([]providers.Provider, []pageMeta, error
 		if friendly == "" {
 			friendly = p.URL
 		}
+		policy := cfg.Common.UnknownPolicy
+		if p.UnknownPolicy != "" {
+			policy = p.UnknownPolicy
+		}
+		include, err := compileMatchers(p.Include)
+		if err != nil {
+			return nil, nil, fmt.Errorf("page %s: include: %w", p.Name, err)
+		}
+		exclude, err := compileMatchers(p.Exclude)
+		if err != nil {
+			return nil, nil, fmt.Errorf("page %s: exclude: %w", p.Name, err)
+		}
+		relabel, err := compileRelabel(p.Relabel)
+		if err != nil {
+			return nil, nil, fmt.Errorf("page %s: relabel: %w", p.Name, err)
+		}
+		weights, err := compileWeights(p.Weights)
+		if err != nil {
+			return nil, nil, fmt.Errorf("page %s: weights: %w", p.Name, err)
+		}
+		maxComponents := cfg.Common.MaxComponents
+		if p.MaxComponents != nil {
+			maxComponents = *p.MaxComponents
+		}
+		meta := pageMeta{Page: p.Name, URL: friendly, UnknownPolicy: policy, Include: include, Exclude: exclude, Relabel: relabel, Weights: weights, MaxComponents: maxComponents}
 		switch p.Type {
 		case "statuspage":
 			ps = append(ps, providers.NewStatuspage(p.Name, p.URL, interval, timeout))
-			metas = append(metas, pageMeta{Provider: "statuspage", Page: p.Name, URL: friendly})
+			meta.Provider = "statuspage"
 		case "instatus":
 			ps = append(ps, providers.NewInstatus(p.Name, p.URL, interval, timeout))
-			metas = append(metas, pageMeta{Provider: "instatus", Page: p.Name, URL: friendly})
+			meta.Provider = "instatus"
 		case "statusio_rss":
 			ps = append(ps, providers.NewStatusIO(p.Name, p.URL, interval, timeout))
-			metas = append(metas, pageMeta{Provider: "statusio_rss", Page: p.Name, URL: friendly})
+			meta.Provider = "statusio_rss"
 		case "azuredevops":
 			ps = append(ps, providers.NewAzureDevOps(p.Name, p.URL, interval, timeout))
-			metas = append(metas, pageMeta{Provider: "azuredevops", Page: p.Name, URL: friendly})
+			meta.Provider = "azuredevops"
 		case "gcp":
 			ps = append(ps, providers.NewGCP(p.Name, p.URL, interval, timeout))
-			metas = append(metas, pageMeta{Provider: "gcp", Page: p.Name, URL: friendly})
+			meta.Provider = "gcp"
 		case "aws_rss":
 			feeds := make([]providers.FeedInput, 0, len(p.Feeds))
 			for _, f := range p.Feeds {
 				feeds = append(feeds, providers.FeedInput{URL: f.URL, Service: f.Service, Region: f.Region})
 			}
 			ps = append(ps, providers.NewAWSRSS(p.Name, feeds, interval, timeout))
-			metas = append(metas, pageMeta{Provider: "aws_rss", Page: p.Name, URL: friendly})
+			meta.Provider = "aws_rss"
 		case "betterstack":
 			ps = append(ps, providers.NewBetterStack(p.Name, p.PageID, p.APIToken, interval, timeout))
-			metas = append(metas, pageMeta{Provider: "betterstack", Page: p.Name, URL: friendly})
+			meta.Provider = "betterstack"
 		case "cloudflare":
 			ps = append(ps, providers.NewCloudflare(p.Name, p.URL, interval, timeout))
-			metas = append(metas, pageMeta{Provider: "cloudflare", Page: p.Name, URL: friendly})
+			meta.Provider = "cloudflare"
 		default:
 			return nil, nil, fmt.Errorf("unknown provider type: %s", p.Type)
 		}
+		metas = append(metas, meta)
 	}
 	return ps, metas, nil
 }
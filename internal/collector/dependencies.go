@@ -0,0 +1,93 @@
+package collector
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/conradoqg/statuspage-exporter/internal/config"
+	"github.com/conradoqg/statuspage-exporter/internal/providers"
+)
+
+type dependency struct {
+	service   string
+	selectors []depSelector
+}
+
+type depSelector struct {
+	page *regexp.Regexp
+	comp matcher
+}
+
+func compileDependencies(deps []config.Dependency) ([]dependency, error) {
+	out := make([]dependency, 0, len(deps))
+	for _, d := range deps {
+		dep := dependency{service: d.Service}
+		for _, s := range d.Components {
+			page, err := compilePattern(s.Page, s.Match)
+			if err != nil {
+				return nil, fmt.Errorf("dependency %s: %w", d.Service, err)
+			}
+			comp, err := compileMatcher(config.ComponentMatcher{Name: s.Component, Group: s.Group, Region: s.Region, Match: s.Match})
+			if err != nil {
+				return nil, fmt.Errorf("dependency %s: %w", d.Service, err)
+			}
+			dep.selectors = append(dep.selectors, depSelector{page: page, comp: comp})
+		}
+		out = append(out, dep)
+	}
+	return out, nil
+}
+
+func (d dependency) matches(page string, c providers.Component) bool {
+	for _, s := range d.selectors {
+		if matchField(s.page, page) && s.comp.matches(c) {
+			return true
+		}
+	}
+	return false
+}
+
+// collectDependencies exports, per internal service, the worst effective status
+// across its mapped vendor components. Services without matches report unknown.
+func (e *Exporter) collectDependencies(ch chan<- prometheus.Metric) {
+	if len(e.deps) == 0 {
+		return
+	}
+	worstBy := make([]providers.NormalizedStatus, len(e.deps))
+	scores := make([]healthAcc, len(e.deps))
+	seen := make(map[string]struct{})
+	for i := range e.providers {
+		res, _, lastKnown, err := e.cached(i)
+		if err != nil || res.Provider == "" {
+			continue
+		}
+		policy := e.metas[i].UnknownPolicy
+		for _, c := range res.Components {
+			st, _, emit := applyUnknownPolicy(policy, c.Status, lastKnown[componentKey(c)])
+			if !emit {
+				continue
+			}
+			for j, d := range e.deps {
+				if !d.matches(res.Page, c) {
+					continue
+				}
+				worstBy[j] = Worst(worstBy[j], st)
+				key := fmt.Sprintf("%s|%s|%s|%s|%s|%s", d.service, res.Provider, res.Page, c.Name, c.Group, c.Region)
+				if _, dup := seen[key]; dup {
+					continue
+				}
+				seen[key] = struct{}{}
+				scores[j].add(e.componentWeight(i, c), e.componentScore(st))
+				ch <- prometheus.MustNewConstMetric(e.depInfo, prometheus.GaugeValue, 1, d.service, res.Provider, res.Page, c.Name, c.Group, c.Region)
+			}
+		}
+	}
+	for j, d := range e.deps {
+		ch <- prometheus.MustNewConstMetric(e.depStatus, prometheus.GaugeValue, e.code(worstBy[j]), d.service)
+		if score, ok := scores[j].score(); ok {
+			ch <- prometheus.MustNewConstMetric(e.depHealth, prometheus.GaugeValue, score, d.service)
+		}
+	}
+}
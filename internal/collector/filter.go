@@ -0,0 +1,146 @@
+package collector
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/conradoqg/statuspage-exporter/internal/events"
+)
+
+// Filter restricts a scrape to a subset of pages. Values of the same kind are
+// OR-ed, different kinds are AND-ed; the zero value matches every page.
+type Filter struct {
+	Pages     []string
+	Providers []string
+	// Extra page labels (see config labels): key -> accepted values
+	Labels map[string][]string
+}
+
+// ParseFilter reads ?page=a&page=b&provider=x&label.team=y style parameters.
+func ParseFilter(q url.Values) Filter {
+	f := Filter{Pages: q["page"], Providers: q["provider"]}
+	for k, vs := range q {
+		if name, ok := strings.CutPrefix(k, "label."); ok && name != "" {
+			if f.Labels == nil {
+				f.Labels = make(map[string][]string)
+			}
+			f.Labels[name] = append(f.Labels[name], vs...)
+		}
+	}
+	return f
+}
+
+// Empty reports whether the filter matches everything.
+func (f Filter) Empty() bool {
+	return len(f.Pages) == 0 && len(f.Providers) == 0 && len(f.Labels) == 0
+}
+
+// MatchPage reports whether page i passes the filter.
+func (e *Exporter) MatchPage(f Filter, i int) bool {
+	m := e.metas[i]
+	if len(f.Pages) > 0 && !contains(f.Pages, m.Page) {
+		return false
+	}
+	if len(f.Providers) > 0 && !contains(f.Providers, m.Provider) {
+		return false
+	}
+	for k, vs := range f.Labels {
+		if !contains(vs, e.pageLabel(i, k)) {
+			return false
+		}
+	}
+	return true
+}
+
+// MatchEvent reports whether the page an event belongs to passes the filter.
+func (e *Exporter) MatchEvent(f Filter, ev events.Event) bool {
+	i, ok := e.pageIndex(ev.Provider, ev.Page)
+	return ok && e.MatchPage(f, i)
+}
+
+// pageIndex returns the index of the page with the given provider and name.
+func (e *Exporter) pageIndex(provider, page string) (int, bool) {
+	for i, m := range e.metas {
+		if m.Provider == provider && m.Page == page {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// pageLabel returns the value of extra label k on page i, empty when unset.
+func (e *Exporter) pageLabel(i int, k string) string {
+	for j, key := range e.labelKeys {
+		if key == k {
+			return e.metas[i].LabelValues[j]
+		}
+	}
+	return ""
+}
+
+func contains(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Filtered returns a collector exposing only the pages matched by f.
+// Service dependency metrics span pages and are left out.
+func (e *Exporter) Filtered(f Filter) prometheus.Collector {
+	return &filteredCollector{e: e, f: f}
+}
+
+type filteredCollector struct {
+	e *Exporter
+	f Filter
+}
+
+func (c *filteredCollector) Describe(ch chan<- *prometheus.Desc) { c.e.Describe(ch) }
+
+func (c *filteredCollector) Collect(ch chan<- prometheus.Metric) {
+	c.e.collect(ch, func(i int) bool { return c.e.MatchPage(c.f, i) })
+}
+
+// collectCounters forwards counter children belonging to pages accepted by keep.
+func (e *Exporter) collectCounters(ch chan<- prometheus.Metric, keep func(i int) bool) {
+	for _, c := range e.counters {
+		if keep == nil {
+			c.Collect(ch)
+			continue
+		}
+		tmp := make(chan prometheus.Metric)
+		go func(c *prometheus.CounterVec) {
+			c.Collect(tmp)
+			close(tmp)
+		}(c)
+		for m := range tmp {
+			if i, ok := e.metricPage(m); ok && keep(i) {
+				ch <- m
+			}
+		}
+	}
+}
+
+// metricPage resolves the page index from a metric's provider/page labels.
+func (e *Exporter) metricPage(m prometheus.Metric) (int, bool) {
+	var d dto.Metric
+	if err := m.Write(&d); err != nil {
+		return 0, false
+	}
+	var provider, page string
+	for _, lp := range d.GetLabel() {
+		switch lp.GetName() {
+		case "provider":
+			provider = lp.GetValue()
+		case "page":
+			page = lp.GetValue()
+		}
+	}
+	return e.pageIndex(provider, page)
+}
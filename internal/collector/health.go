@@ -0,0 +1,135 @@
+package collector
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/conradoqg/statuspage-exporter/internal/config"
+	"github.com/conradoqg/statuspage-exporter/internal/providers"
+)
+
+// Health scores (0-100):
+//
+//	component score = max(0, 100 - penalty[effective status])
+//	group score     = Σ(weight × component score) / Σ weight, over the group's components
+//	page score      = clamp(Σ(weight × component score) / Σ weight - incident_penalty × open incidents, 0, 100)
+//	service score   = Σ(weight × component score) / Σ weight, over the mapped components
+//
+// Effective status is the status after the page's unknown policy; omitted
+// components and zero weights do not contribute. A page without components
+// starts at 100.
+//
+// Open incidents are those the provider details (Statuspage, Cloudflare,
+// Google Cloud); the open_incidents fallback that counts non-operational
+// components is not penalized twice.
+
+type weightRule struct {
+	m      matcher
+	weight float64
+}
+
+func compileWeights(rules []config.WeightRule) ([]weightRule, error) {
+	out := make([]weightRule, 0, len(rules))
+	for _, r := range rules {
+		m, err := compileMatcher(r.ComponentMatcher)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, weightRule{m: m, weight: r.Weight})
+	}
+	return out, nil
+}
+
+func buildPenalties(cfg map[string]float64) map[providers.NormalizedStatus]float64 {
+	out := make(map[providers.NormalizedStatus]float64, len(cfg))
+	for k, v := range cfg {
+		if st, ok := providers.ParseStatus(k); ok {
+			out[st] = v
+		}
+	}
+	return out
+}
+
+// componentWeight returns the weight of c on page i (first matching rule, default 1).
+func (e *Exporter) componentWeight(i int, c providers.Component) float64 {
+	for _, w := range e.metas[i].Weights {
+		if w.m.matches(c) {
+			return w.weight
+		}
+	}
+	return 1
+}
+
+func (e *Exporter) componentScore(st providers.NormalizedStatus) float64 {
+	s := 100 - e.penalties[st]
+	if s < 0 {
+		return 0
+	}
+	return s
+}
+
+// healthAcc accumulates a weighted mean of component scores.
+type healthAcc struct {
+	sum    float64
+	weight float64
+}
+
+func (a *healthAcc) add(weight, score float64) {
+	a.sum += weight * score
+	a.weight += weight
+}
+
+// score returns the weighted mean; ok is false when nothing contributed.
+func (a healthAcc) score() (float64, bool) {
+	if a.weight == 0 {
+		return 0, false
+	}
+	return a.sum / a.weight, true
+}
+
+func (e *Exporter) collectHealth(ch chan<- prometheus.Metric, i int, res providers.Result, lastKnown map[string]providers.NormalizedStatus) {
+	policy := e.metas[i].UnknownPolicy
+	var page healthAcc
+	groups := make(map[string]*healthAcc)
+	var order []string
+	for _, c := range res.Components {
+		st, _, emit := applyUnknownPolicy(policy, c.Status, lastKnown[componentKey(c)])
+		if !emit {
+			continue
+		}
+		w, s := e.componentWeight(i, c), e.componentScore(st)
+		page.add(w, s)
+		if c.Group == "" {
+			continue
+		}
+		g, ok := groups[c.Group]
+		if !ok {
+			g = &healthAcc{}
+			groups[c.Group] = g
+			order = append(order, c.Group)
+		}
+		g.add(w, s)
+	}
+	score, ok := page.score()
+	if !ok {
+		score = 100
+	}
+	// Only incidents with details count: without them OpenIncidents falls
+	// back to the number of non-operational components, already penalized
+	score -= e.incidentPenalty * float64(len(res.Incidents))
+	e.emit(ch, i, e.pageHealth, clampScore(score), res.Provider, res.Page)
+	for _, name := range order {
+		if s, ok := groups[name].score(); ok {
+			e.emit(ch, i, e.groupHealth, s, res.Provider, res.Page, name)
+		}
+	}
+}
+
+func clampScore(s float64) float64 {
+	if s < 0 {
+		return 0
+	}
+	if s > 100 {
+		return 100
+	}
+	return s
+}
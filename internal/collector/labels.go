@@ -0,0 +1,65 @@
+package collector
+
+import (
+	"sort"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/conradoqg/statuspage-exporter/internal/config"
+)
+
+// extraLabelKeys returns the sorted union of common and page label names.
+// Every series carries all of them; pages without a value get an empty label,
+// which Prometheus treats as absent.
+func extraLabelKeys(cfg *config.Config) []string {
+	set := make(map[string]struct{})
+	for k := range cfg.Common.Labels {
+		set[k] = struct{}{}
+	}
+	for _, p := range cfg.Pages {
+		for k := range p.Labels {
+			set[k] = struct{}{}
+		}
+	}
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// labelValues resolves the extra label values for page i; page labels override common ones.
+func (e *Exporter) labelValues(cfg *config.Config, i int) []string {
+	page := cfg.Pages[i].Labels
+	vals := make([]string, len(e.labelKeys))
+	for j, k := range e.labelKeys {
+		if v, ok := page[k]; ok {
+			vals[j] = v
+		} else {
+			vals[j] = cfg.Common.Labels[k]
+		}
+	}
+	return vals
+}
+
+// newDesc builds a per-page descriptor named <prefix>_<name> carrying the extra labels.
+func (e *Exporter) newDesc(name, help string, labels ...string) *prometheus.Desc {
+	return prometheus.NewDesc(e.prefix+"_"+name, help, append(labels, e.labelKeys...), nil)
+}
+
+func (e *Exporter) newCounterVec(name, help string, labels ...string) *prometheus.CounterVec {
+	c := prometheus.NewCounterVec(prometheus.CounterOpts{Name: e.prefix + "_" + name, Help: help}, append(labels, e.labelKeys...))
+	e.counters = append(e.counters, c)
+	return c
+}
+
+// counter returns the child of vec for page i, appending the page's extra label values.
+func (e *Exporter) counter(vec *prometheus.CounterVec, i int, labels ...string) prometheus.Counter {
+	return vec.WithLabelValues(append(labels, e.metas[i].LabelValues...)...)
+}
+
+// emit sends a gauge for page i, appending the page's extra label values.
+func (e *Exporter) emit(ch chan<- prometheus.Metric, i int, desc *prometheus.Desc, v float64, labels ...string) {
+	ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, v, append(labels, e.metas[i].LabelValues...)...)
+}
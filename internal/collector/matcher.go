@@ -0,0 +1,74 @@
+package collector
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/conradoqg/statuspage-exporter/internal/config"
+	"github.com/conradoqg/statuspage-exporter/internal/providers"
+)
+
+// matcher is a compiled config.ComponentMatcher; nil fields match anything.
+type matcher struct {
+	name   *regexp.Regexp
+	group  *regexp.Regexp
+	region *regexp.Regexp
+}
+
+func compileMatchers(ms []config.ComponentMatcher) ([]matcher, error) {
+	out := make([]matcher, 0, len(ms))
+	for _, m := range ms {
+		cm, err := compileMatcher(m)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, cm)
+	}
+	return out, nil
+}
+
+func compileMatcher(m config.ComponentMatcher) (matcher, error) {
+	var out matcher
+	var err error
+	if out.name, err = compilePattern(m.Name, m.Match); err != nil {
+		return out, err
+	}
+	if out.group, err = compilePattern(m.Group, m.Match); err != nil {
+		return out, err
+	}
+	if out.region, err = compilePattern(m.Region, m.Match); err != nil {
+		return out, err
+	}
+	return out, nil
+}
+
+// compilePattern turns a glob or regex into an anchored regexp; empty means "any".
+func compilePattern(p, syntax string) (*regexp.Regexp, error) {
+	if p == "" {
+		return nil, nil
+	}
+	if syntax == config.MatchRegex {
+		return regexp.Compile("^(?:" + p + ")$")
+	}
+	q := regexp.QuoteMeta(p)
+	q = strings.ReplaceAll(q, `\*`, ".*")
+	q = strings.ReplaceAll(q, `\?`, ".")
+	return regexp.Compile("^" + q + "$")
+}
+
+func (m matcher) matches(c providers.Component) bool {
+	return matchField(m.name, c.Name) && matchField(m.group, c.Group) && matchField(m.region, c.Region)
+}
+
+func matchField(re *regexp.Regexp, v string) bool {
+	return re == nil || re.MatchString(v)
+}
+
+func matchAny(ms []matcher, c providers.Component) bool {
+	for _, m := range ms {
+		if m.matches(c) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/conradoqg/statuspage-exporter/internal/config"
+	"github.com/conradoqg/statuspage-exporter/internal/providers"
+)
+
+func TestMatcher(t *testing.T) {
+	api := providers.Component{Name: "API Requests", Group: "Core", Region: "us-east-1"}
+	tests := []struct {
+		name string
+		m    config.ComponentMatcher
+		want bool
+	}{
+		{"empty matches anything", config.ComponentMatcher{}, true},
+		{"glob exact", config.ComponentMatcher{Name: "API Requests"}, true},
+		{"glob is anchored", config.ComponentMatcher{Name: "API"}, false},
+		{"glob star", config.ComponentMatcher{Name: "API*"}, true},
+		{"glob question mark", config.ComponentMatcher{Region: "us-east-?"}, true},
+		{"glob quotes regex metacharacters", config.ComponentMatcher{Name: "API.Requests"}, false},
+		{"glob is case sensitive", config.ComponentMatcher{Name: "api*"}, false},
+		{"all fields must match", config.ComponentMatcher{Name: "API*", Group: "Edge"}, false},
+		{"all fields match", config.ComponentMatcher{Name: "API*", Group: "Core", Region: "us-*"}, true},
+		{"regex", config.ComponentMatcher{Name: "API (Requests|Calls)", Match: config.MatchRegex}, true},
+		{"regex is anchored", config.ComponentMatcher{Name: "Requests", Match: config.MatchRegex}, false},
+		{"regex star is not a glob", config.ComponentMatcher{Region: "us-*", Match: config.MatchRegex}, false},
+		{"regex applies to every field", config.ComponentMatcher{Group: "C.re", Region: `us-\w+-\d`, Match: config.MatchRegex}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := compileMatcher(tt.m)
+			if err != nil {
+				t.Fatalf("compileMatcher: %v", err)
+			}
+			if got := m.matches(api); got != tt.want {
+				t.Errorf("matches = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchAny(t *testing.T) {
+	ms, err := compileMatchers([]config.ComponentMatcher{{Name: "Website"}, {Group: "Core"}})
+	if err != nil {
+		t.Fatalf("compileMatchers: %v", err)
+	}
+	tests := []struct {
+		c    providers.Component
+		want bool
+	}{
+		{providers.Component{Name: "Website"}, true},
+		{providers.Component{Name: "API", Group: "Core"}, true},
+		{providers.Component{Name: "API", Group: "Edge"}, false},
+	}
+	for _, tt := range tests {
+		if got := matchAny(ms, tt.c); got != tt.want {
+			t.Errorf("matchAny(%+v) = %v, want %v", tt.c, got, tt.want)
+		}
+	}
+	if matchAny(nil, providers.Component{Name: "Website"}) {
+		t.Error("no matchers should match nothing")
+	}
+}
+
+func TestCompileMatchersInvalidRegex(t *testing.T) {
+	if _, err := compileMatchers([]config.ComponentMatcher{{Name: "(", Match: config.MatchRegex}}); err == nil {
+		t.Fatal("expected an error")
+	}
+}
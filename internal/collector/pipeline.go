@@ -0,0 +1,85 @@
+package collector
+
+import (
+	"sort"
+
+	"github.com/conradoqg/statuspage-exporter/internal/logx"
+	"github.com/conradoqg/statuspage-exporter/internal/providers"
+)
+
+// process applies the page's component pipeline to a freshly fetched result
+// before it is cached, so every consumer sees the same view.
+func (e *Exporter) process(i int, res providers.Result) providers.Result {
+	res.Components = e.filterComponents(i, res)
+	res.Components = relabelComponents(e.metas[i].Relabel, res.Components)
+	res.Components = e.limitComponents(i, res)
+	return res
+}
+
+// filterComponents applies include/exclude rules, which match the vendor's
+// original component names.
+func (e *Exporter) filterComponents(i int, res providers.Result) []providers.Component {
+	m := e.metas[i]
+	if len(m.Include) == 0 && len(m.Exclude) == 0 {
+		return res.Components
+	}
+	kept := make([]providers.Component, 0, len(res.Components))
+	for _, c := range res.Components {
+		if len(m.Include) > 0 && !matchAny(m.Include, c) {
+			continue
+		}
+		if matchAny(m.Exclude, c) {
+			continue
+		}
+		kept = append(kept, c)
+	}
+	e.counter(e.filtered, i, res.Provider, res.Page).Add(float64(len(res.Components) - len(kept)))
+	return kept
+}
+
+// limitComponents enforces max_components and what max_total_components
+// leaves after the other pages' last successful fetches. Components are
+// sorted by group/name/region so the same ones survive on every refresh.
+func (e *Exporter) limitComponents(i int, res providers.Result) []providers.Component {
+	limit := e.metas[i].MaxComponents
+	limited := limit > 0
+	if e.totalLimit > 0 {
+		budget := e.totalLimit
+		for j, ce := range e.caches {
+			if j != i {
+				budget -= int(ce.kept.Load())
+			}
+		}
+		budget = max(budget, 0)
+		if !limited || budget < limit {
+			limit, limited = budget, true
+		}
+	}
+	if !limited {
+		e.caches[i].kept.Store(int64(len(res.Components)))
+		return res.Components
+	}
+	dropped := len(res.Components) - limit
+	if dropped <= 0 {
+		e.counter(e.dropped, i, res.Provider, res.Page).Add(0)
+		e.caches[i].kept.Store(int64(len(res.Components)))
+		return res.Components
+	}
+	comps := append([]providers.Component(nil), res.Components...)
+	sort.SliceStable(comps, func(a, b int) bool {
+		ca, cb := comps[a], comps[b]
+		if ca.Group != cb.Group {
+			return ca.Group < cb.Group
+		}
+		if ca.Name != cb.Name {
+			return ca.Name < cb.Name
+		}
+		return ca.Region < cb.Region
+	})
+	e.counter(e.dropped, i, res.Provider, res.Page).Add(float64(dropped))
+	if !e.caches[i].limitWarned.Swap(true) {
+		logx.Warnf("component limit reached provider=%s page=%s limit=%d received=%d; dropping the rest", res.Provider, res.Page, limit, len(res.Components))
+	}
+	e.caches[i].kept.Store(int64(limit))
+	return comps[:limit]
+}
@@ -0,0 +1,102 @@
+package collector
+
+import (
+	"reflect"
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/conradoqg/statuspage-exporter/internal/providers"
+)
+
+// newTestExporter returns an Exporter for the given pages without starting
+// their refresh loops.
+func newTestExporter(metas ...pageMeta) *Exporter {
+	e := &Exporter{prefix: "test", metas: metas, caches: make([]*cacheEntry, len(metas))}
+	for i := range e.caches {
+		e.caches[i] = &cacheEntry{}
+	}
+	e.dropped = e.newCounterVec("components_dropped_total", "", "provider", "page")
+	return e
+}
+
+func TestLimitComponents(t *testing.T) {
+	comps := []providers.Component{
+		{Name: "Web", Group: "B"},
+		{Name: "API", Group: "B", Region: "us"},
+		{Name: "API", Group: "B", Region: "eu"},
+		{Name: "Zeta", Group: "A"},
+	}
+	tests := []struct {
+		name        string
+		limit       int
+		want        []providers.Component
+		wantDropped float64
+	}{
+		{"unlimited", 0, comps, 0},
+		{"under the limit", 4, comps, 0},
+		{"sorted by group, name and region", 3, []providers.Component{comps[3], comps[2], comps[1]}, 1},
+		{"one", 1, []providers.Component{comps[3]}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newTestExporter(pageMeta{Provider: "statuspage", Page: "p", MaxComponents: tt.limit})
+			res := providers.Result{Provider: "statuspage", Page: "p", Components: append([]providers.Component(nil), comps...)}
+			got := e.limitComponents(0, res)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+			if !reflect.DeepEqual(res.Components, comps) {
+				t.Errorf("input modified: %+v", res.Components)
+			}
+			var m dto.Metric
+			if err := e.dropped.WithLabelValues("statuspage", "p").Write(&m); err != nil {
+				t.Fatalf("read counter: %v", err)
+			}
+			if d := m.GetCounter().GetValue(); d != tt.wantDropped {
+				t.Errorf("dropped = %v, want %v", d, tt.wantDropped)
+			}
+		})
+	}
+}
+
+func TestLimitComponentsTotal(t *testing.T) {
+	comps := func(n int) []providers.Component {
+		out := make([]providers.Component, n)
+		for j := range out {
+			out[j] = providers.Component{Name: string(rune('A' + j))}
+		}
+		return out
+	}
+	tests := []struct {
+		name      string
+		pageLimit int
+		total     int
+		others    int64
+		received  int
+		want      int
+	}{
+		{"under the budget", 0, 10, 4, 5, 5},
+		{"budget left by other pages", 0, 10, 7, 5, 3},
+		{"budget exhausted", 0, 10, 12, 5, 0},
+		{"page limit is lower", 2, 10, 4, 5, 2},
+		{"budget is lower than the page limit", 4, 10, 8, 5, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newTestExporter(
+				pageMeta{Provider: "statuspage", Page: "p", MaxComponents: tt.pageLimit},
+				pageMeta{Provider: "statuspage", Page: "other"},
+			)
+			e.totalLimit = tt.total
+			e.caches[1].kept.Store(tt.others)
+			got := e.limitComponents(0, providers.Result{Provider: "statuspage", Page: "p", Components: comps(tt.received)})
+			if len(got) != tt.want {
+				t.Errorf("kept %d, want %d", len(got), tt.want)
+			}
+			if k := e.caches[0].kept.Load(); k != int64(tt.want) {
+				t.Errorf("recorded %d, want %d", k, tt.want)
+			}
+		})
+	}
+}
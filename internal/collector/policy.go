@@ -0,0 +1,58 @@
+package collector
+
+import (
+	"github.com/conradoqg/statuspage-exporter/internal/config"
+	"github.com/conradoqg/statuspage-exporter/internal/providers"
+)
+
+// applyUnknownPolicy resolves the status to expose for a component according to
+// the page's unknown-status policy. It returns the effective status, the value
+// for statuspage_component_up and whether the component should be emitted at all.
+//
+//   - up:         unknown is exposed as up=1
+//   - down:       unknown is exposed as up=0
+//   - last_known: the last definite status is reused; without history it is up=0
+//   - omit:       components with unknown status are not emitted
+func applyUnknownPolicy(policy string, st, lastKnown providers.NormalizedStatus) (providers.NormalizedStatus, float64, bool) {
+	if st == providers.StatusUnknown {
+		switch policy {
+		case config.UnknownOmit:
+			return st, 0, false
+		case config.UnknownUp:
+			return st, 1, true
+		case config.UnknownLastKnown:
+			st = lastKnown
+		}
+	}
+	if st == providers.StatusOperational {
+		return st, 1, true
+	}
+	return st, 0, true
+}
+
+// rememberKnown returns a copy of prev updated with every definite status in comps.
+func rememberKnown(prev map[string]providers.NormalizedStatus, comps []providers.Component) map[string]providers.NormalizedStatus {
+	next := make(map[string]providers.NormalizedStatus, len(prev)+len(comps))
+	for k, v := range prev {
+		next[k] = v
+	}
+	for _, c := range comps {
+		if c.Status != providers.StatusUnknown {
+			next[componentKey(c)] = c.Status
+		}
+	}
+	return next
+}
+
+func componentKey(c providers.Component) string {
+	return c.Name + "|" + c.Group + "|" + c.Region
+}
+
+// Worst returns the more severe of two statuses. NormalizedStatus values are
+// declared in increasing severity, with unknown ranking below operational.
+func Worst(a, b providers.NormalizedStatus) providers.NormalizedStatus {
+	if b > a {
+		return b
+	}
+	return a
+}
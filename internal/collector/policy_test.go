@@ -0,0 +1,58 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/conradoqg/statuspage-exporter/internal/config"
+	"github.com/conradoqg/statuspage-exporter/internal/providers"
+)
+
+func TestApplyUnknownPolicy(t *testing.T) {
+	const (
+		unknown     = providers.StatusUnknown
+		operational = providers.StatusOperational
+		degraded    = providers.StatusDegraded
+	)
+	tests := []struct {
+		name      string
+		policy    string
+		st        providers.NormalizedStatus
+		lastKnown providers.NormalizedStatus
+		wantSt    providers.NormalizedStatus
+		wantUp    float64
+		wantEmit  bool
+	}{
+		{"operational", config.UnknownDown, operational, unknown, operational, 1, true},
+		{"degraded", config.UnknownUp, degraded, operational, degraded, 0, true},
+		{"up", config.UnknownUp, unknown, degraded, unknown, 1, true},
+		{"down", config.UnknownDown, unknown, operational, unknown, 0, true},
+		{"omit", config.UnknownOmit, unknown, operational, unknown, 0, false},
+		{"omit keeps known statuses", config.UnknownOmit, degraded, unknown, degraded, 0, true},
+		{"last known operational", config.UnknownLastKnown, unknown, operational, operational, 1, true},
+		{"last known degraded", config.UnknownLastKnown, unknown, degraded, degraded, 0, true},
+		{"last known without history", config.UnknownLastKnown, unknown, unknown, unknown, 0, true},
+		{"last known ignored for known statuses", config.UnknownLastKnown, degraded, operational, degraded, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st, up, emit := applyUnknownPolicy(tt.policy, tt.st, tt.lastKnown)
+			if st != tt.wantSt || up != tt.wantUp || emit != tt.wantEmit {
+				t.Errorf("got (%s, %v, %v), want (%s, %v, %v)", st, up, emit, tt.wantSt, tt.wantUp, tt.wantEmit)
+			}
+		})
+	}
+}
+
+func TestRememberKnown(t *testing.T) {
+	prev := map[string]providers.NormalizedStatus{"API||": providers.StatusDegraded}
+	next := rememberKnown(prev, []providers.Component{
+		{Name: "API", Status: providers.StatusUnknown},
+		{Name: "Web", Status: providers.StatusOperational},
+	})
+	if next["API||"] != providers.StatusDegraded || next["Web||"] != providers.StatusOperational {
+		t.Errorf("got %v", next)
+	}
+	if len(prev) != 1 {
+		t.Errorf("prev modified: %v", prev)
+	}
+}
@@ -0,0 +1,106 @@
+package collector
+
+import (
+	"strings"
+
+	"github.com/conradoqg/statuspage-exporter/internal/config"
+)
+
+// Continents used by the built-in region table
+const (
+	continentGlobal       = "global"
+	continentNorthAmerica = "north_america"
+	continentSouthAmerica = "south_america"
+	continentEurope       = "europe"
+	continentAsia         = "asia"
+	continentOceania      = "oceania"
+	continentAfrica       = "africa"
+	continentMiddleEast   = "middle_east"
+)
+
+type regionInfo struct {
+	canonical string
+	continent string
+}
+
+// builtinRegions maps vendor region spellings (normalized with regionKey) to a
+// canonical region. Sources: AWS/GCP/Azure region codes and display names,
+// Azure DevOps geographies and common status-page group names.
+var builtinRegions = buildRegionTable(map[regionInfo][]string{
+	{"global", continentGlobal}: {"global", "worldwide", "all regions", "multi-region", "multi region"},
+
+	{"north-america", continentNorthAmerica}: {"north america", "americas", "america", "na", "amer"},
+	{"us", continentNorthAmerica}:            {"us", "usa", "united states", "us-gov"},
+	{"us-east", continentNorthAmerica}:       {"us-east", "us east", "us-east-1", "us-east-2", "us-east1", "us-east4", "us-east5", "east us", "east us 2", "eastus", "eastus2", "n. virginia", "virginia", "ohio"},
+	{"us-central", continentNorthAmerica}:    {"us-central", "us central", "us-central1", "central us", "centralus", "north central us", "south central us", "west central us", "iowa", "texas"},
+	{"us-west", continentNorthAmerica}:       {"us-west", "us west", "us-west-1", "us-west-2", "us-west1", "us-west2", "us-west3", "us-west4", "west us", "west us 2", "west us 3", "westus", "westus2", "oregon", "n. california", "california"},
+	{"canada", continentNorthAmerica}:        {"ca", "canada", "ca-central-1", "canada central", "canada east", "northamerica-northeast1", "northamerica-northeast2", "montreal", "toronto"},
+	{"mexico", continentNorthAmerica}:        {"mexico", "mx-central-1", "mexico central"},
+
+	{"south-america", continentSouthAmerica}: {"south america", "latam", "latin america", "sa"},
+	{"brazil", continentSouthAmerica}:        {"br", "brazil", "sa-east-1", "brazil south", "southamerica-east1", "sao paulo", "são paulo"},
+	{"chile", continentSouthAmerica}:         {"chile", "southamerica-west1", "santiago"},
+
+	{"europe", continentEurope}:     {"europe", "eu", "emea"},
+	{"eu-west", continentEurope}:    {"eu-west", "eu west", "eu-west-1", "west europe", "westeurope", "europe-west1", "ireland", "belgium", "north europe", "northeurope", "netherlands"},
+	{"uk", continentEurope}:         {"uk", "united kingdom", "gb", "eu-west-2", "uk south", "uk west", "europe-west2", "london"},
+	{"eu-central", continentEurope}: {"eu-central", "eu central", "eu-central-1", "eu-central-2", "germany", "germany west central", "europe-west3", "frankfurt", "zurich", "switzerland north", "europe-west6"},
+	{"eu-south", continentEurope}:   {"eu-south", "eu south", "eu-south-1", "eu-south-2", "italy north", "europe-west8", "milan", "spain", "europe-southwest1", "madrid"},
+	{"eu-north", continentEurope}:   {"eu-north", "eu north", "eu-north-1", "sweden central", "europe-north1", "stockholm", "finland", "norway east"},
+	{"france", continentEurope}:     {"fr", "france", "eu-west-3", "france central", "europe-west9", "paris"},
+
+	{"asia", continentAsia}:              {"asia", "apac", "asia pacific", "ap"},
+	{"asia-east", continentAsia}:         {"asia-east", "east asia", "eastasia", "asia-east1", "asia-east2", "ap-east-1", "hong kong", "taiwan"},
+	{"asia-northeast", continentAsia}:    {"asia-northeast", "ap-northeast-1", "ap-northeast-2", "ap-northeast-3", "asia-northeast1", "asia-northeast2", "asia-northeast3", "japan", "japan east", "japan west", "jp", "tokyo", "osaka", "korea", "korea central", "kr", "seoul"},
+	{"asia-southeast", continentAsia}:    {"asia-southeast", "ap-southeast-1", "ap-southeast-3", "asia-southeast1", "asia-southeast2", "southeast asia", "southeastasia", "singapore", "sg", "jakarta", "indonesia"},
+	{"asia-south", continentAsia}:        {"asia-south", "ap-south-1", "ap-south-2", "asia-south1", "asia-south2", "india", "in", "central india", "south india", "west india", "mumbai", "delhi"},
+	{"middle-east", continentMiddleEast}: {"middle east", "me", "me-south-1", "me-central-1", "me-west1", "me-central1", "uae", "uae north", "qatar central", "israel central", "bahrain", "dubai", "tel aviv", "doha"},
+
+	{"oceania", continentOceania}:   {"oceania", "anz"},
+	{"australia", continentOceania}: {"au", "australia", "ap-southeast-2", "ap-southeast-4", "australia east", "australia southeast", "australia-southeast1", "australia-southeast2", "sydney", "melbourne"},
+
+	{"africa", continentAfrica}: {"africa", "af-south-1", "south africa", "south africa north", "africa-south1", "za", "cape town", "johannesburg"},
+})
+
+func buildRegionTable(groups map[regionInfo][]string) map[string]regionInfo {
+	out := make(map[string]regionInfo)
+	for info, names := range groups {
+		for _, n := range names {
+			out[regionKey(n)] = info
+		}
+	}
+	return out
+}
+
+// regionKey normalizes a region spelling for lookups: lowercase, trimmed,
+// underscores and repeated whitespace collapsed into single spaces.
+func regionKey(s string) string {
+	s = strings.ToLower(strings.ReplaceAll(s, "_", " "))
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// buildRegions merges config overrides over the built-in table.
+func buildRegions(overrides map[string]config.Region) map[string]regionInfo {
+	out := make(map[string]regionInfo, len(builtinRegions)+len(overrides))
+	for k, v := range builtinRegions {
+		out[k] = v
+	}
+	for k, v := range overrides {
+		out[regionKey(k)] = regionInfo{canonical: v.CanonicalRegion, continent: v.Continent}
+	}
+	return out
+}
+
+// normalizeRegion returns the canonical region and continent for a vendor
+// region. Unrecognized regions keep a slug of the original spelling and an
+// empty continent; empty regions stay empty.
+func (e *Exporter) normalizeRegion(region string) (canonical, continent string) {
+	key := regionKey(region)
+	if key == "" {
+		return "", ""
+	}
+	if info, ok := e.regions[key]; ok {
+		return info.canonical, info.continent
+	}
+	return strings.ReplaceAll(key, " ", "-"), ""
+}
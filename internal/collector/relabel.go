@@ -0,0 +1,105 @@
+package collector
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/conradoqg/statuspage-exporter/internal/config"
+	"github.com/conradoqg/statuspage-exporter/internal/providers"
+)
+
+type relabelRule struct {
+	source      []string
+	separator   string
+	regex       *regexp.Regexp
+	target      string
+	replacement string
+	action      string
+}
+
+func compileRelabel(rules []config.RelabelRule) ([]relabelRule, error) {
+	out := make([]relabelRule, 0, len(rules))
+	for _, r := range rules {
+		rr := relabelRule{
+			source:      r.SourceLabels,
+			separator:   r.Separator,
+			target:      r.TargetLabel,
+			replacement: r.Replacement,
+			action:      r.Action,
+		}
+		if rr.separator == "" {
+			rr.separator = ";"
+		}
+		if rr.action == "" {
+			rr.action = config.RelabelReplace
+		}
+		if rr.action == config.RelabelReplace && rr.replacement == "" {
+			rr.replacement = "$1"
+		}
+		expr := r.Regex
+		if expr == "" {
+			expr = "(.*)"
+		}
+		re, err := regexp.Compile("^(?:" + expr + ")$")
+		if err != nil {
+			return nil, err
+		}
+		rr.regex = re
+		out = append(out, rr)
+	}
+	return out, nil
+}
+
+// relabelComponents applies rules in order; components dropped by a rule are removed.
+func relabelComponents(rules []relabelRule, comps []providers.Component) []providers.Component {
+	if len(rules) == 0 {
+		return comps
+	}
+	out := make([]providers.Component, 0, len(comps))
+	for _, c := range comps {
+		if keep := relabel(rules, &c); keep {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func relabel(rules []relabelRule, c *providers.Component) bool {
+	for _, r := range rules {
+		vals := make([]string, len(r.source))
+		for j, l := range r.source {
+			vals[j] = *componentLabel(c, l)
+		}
+		val := strings.Join(vals, r.separator)
+		switch r.action {
+		case config.RelabelDrop:
+			if r.regex.MatchString(val) {
+				return false
+			}
+		case config.RelabelKeep:
+			if !r.regex.MatchString(val) {
+				return false
+			}
+		case config.RelabelSet:
+			*componentLabel(c, r.target) = r.replacement
+		default:
+			idx := r.regex.FindStringSubmatchIndex(val)
+			if idx == nil {
+				continue
+			}
+			*componentLabel(c, r.target) = string(r.regex.ExpandString(nil, r.replacement, val, idx))
+		}
+	}
+	return true
+}
+
+func componentLabel(c *providers.Component, name string) *string {
+	switch name {
+	case "group":
+		return &c.Group
+	case "region":
+		return &c.Region
+	default:
+		return &c.Name
+	}
+}
@@ -0,0 +1,112 @@
+package collector
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/conradoqg/statuspage-exporter/internal/config"
+	"github.com/conradoqg/statuspage-exporter/internal/providers"
+)
+
+func TestRelabelComponents(t *testing.T) {
+	comps := []providers.Component{
+		{Name: "API (us-east-1)", Group: "Compute"},
+		{Name: "Website", Group: "Marketing"},
+		{Name: "Dashboard", Group: "Compute", Region: "eu"},
+	}
+	tests := []struct {
+		name  string
+		rules []config.RelabelRule
+		want  []providers.Component
+	}{
+		{
+			name: "no rules",
+			want: comps,
+		},
+		{
+			name: "replace with capture groups",
+			rules: []config.RelabelRule{
+				{SourceLabels: []string{"name"}, Regex: `(.+) \((.+)\)`, TargetLabel: "region", Replacement: "$2"},
+				{SourceLabels: []string{"name"}, Regex: `(.+) \(.+\)`, TargetLabel: "name"},
+			},
+			want: []providers.Component{
+				{Name: "API", Group: "Compute", Region: "us-east-1"},
+				{Name: "Website", Group: "Marketing"},
+				{Name: "Dashboard", Group: "Compute", Region: "eu"},
+			},
+		},
+		{
+			name: "replace leaves non-matching values alone",
+			rules: []config.RelabelRule{
+				{SourceLabels: []string{"group"}, Regex: "Marketing", TargetLabel: "group", Replacement: "Web"},
+			},
+			want: []providers.Component{
+				{Name: "API (us-east-1)", Group: "Compute"},
+				{Name: "Website", Group: "Web"},
+				{Name: "Dashboard", Group: "Compute", Region: "eu"},
+			},
+		},
+		{
+			name: "regex is anchored",
+			rules: []config.RelabelRule{
+				{SourceLabels: []string{"name"}, Regex: "API", Action: config.RelabelDrop},
+			},
+			want: comps,
+		},
+		{
+			name: "drop joins source labels with the separator",
+			rules: []config.RelabelRule{
+				{SourceLabels: []string{"group", "region"}, Separator: "/", Regex: "Compute/eu", Action: config.RelabelDrop},
+			},
+			want: comps[:2],
+		},
+		{
+			name: "keep",
+			rules: []config.RelabelRule{
+				{SourceLabels: []string{"group"}, Regex: "Compute", Action: config.RelabelKeep},
+			},
+			want: []providers.Component{comps[0], comps[2]},
+		},
+		{
+			name: "set",
+			rules: []config.RelabelRule{
+				{TargetLabel: "region", Replacement: "global", Action: config.RelabelSet},
+			},
+			want: []providers.Component{
+				{Name: "API (us-east-1)", Group: "Compute", Region: "global"},
+				{Name: "Website", Group: "Marketing", Region: "global"},
+				{Name: "Dashboard", Group: "Compute", Region: "global"},
+			},
+		},
+		{
+			name: "rules see the output of earlier rules",
+			rules: []config.RelabelRule{
+				{SourceLabels: []string{"name"}, Regex: "Website", TargetLabel: "name", Replacement: "Marketing site"},
+				{SourceLabels: []string{"name"}, Regex: "Marketing.*", Action: config.RelabelDrop},
+			},
+			want: []providers.Component{comps[0], comps[2]},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := compileRelabel(tt.rules)
+			if err != nil {
+				t.Fatalf("compileRelabel: %v", err)
+			}
+			in := append([]providers.Component(nil), comps...)
+			got := relabelComponents(rules, in)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+			if !reflect.DeepEqual(in, comps) {
+				t.Errorf("input modified: %+v", in)
+			}
+		})
+	}
+}
+
+func TestCompileRelabelInvalidRegex(t *testing.T) {
+	if _, err := compileRelabel([]config.RelabelRule{{SourceLabels: []string{"name"}, Regex: "("}}); err == nil {
+		t.Fatal("expected an error")
+	}
+}
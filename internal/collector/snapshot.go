@@ -0,0 +1,134 @@
+package collector
+
+import (
+	"time"
+
+	"github.com/conradoqg/statuspage-exporter/internal/providers"
+)
+
+// PageState is the exporter's normalized view of one configured page, as of
+// its last refresh. It is what /metrics exposes, in structured form.
+type PageState struct {
+	Provider string
+	Page     string
+	URL      string
+	Labels   map[string]string
+	// Components after filters, relabeling, limits and the unknown policy
+	Components    []ComponentState
+	Incidents     []providers.Incident
+	OpenIncidents int
+	// Upcoming and in-progress scheduled maintenances
+	Maintenances []providers.Incident
+	// Vendor-reported page update time (or latest component update)
+	UpdatedAt time.Time
+	// Last fetch attempt; zero until the first fetch completes
+	FetchedAt time.Time
+	// First successful fetch of this process; changes before it were not
+	// observed
+	BaselineAt time.Time
+	Duration   time.Duration
+	Err        error
+}
+
+// ComponentState is a component with its effective status.
+type ComponentState struct {
+	providers.Component
+	Up              bool
+	CanonicalRegion string
+	Continent       string
+	// When the component entered its current status, as observed by the
+	// exporter (vendor update time for statuses present at startup)
+	Since time.Time
+}
+
+// Worst returns the most severe component status on the page.
+func (p PageState) Worst() providers.NormalizedStatus {
+	w := providers.StatusUnknown
+	for _, c := range p.Components {
+		w = Worst(w, c.Status)
+	}
+	return w
+}
+
+// Snapshot returns the cached state of every page, in config order. It never
+// triggers a fetch.
+func (e *Exporter) Snapshot() []PageState {
+	out := make([]PageState, 0, len(e.metas))
+	for i := range e.metas {
+		out = append(out, e.pageState(i))
+	}
+	return out
+}
+
+// PageStateByName returns the cached state of the first page named name.
+func (e *Exporter) PageStateByName(name string) (PageState, bool) {
+	for i, m := range e.metas {
+		if m.Page == name {
+			return e.pageState(i), true
+		}
+	}
+	return PageState{}, false
+}
+
+func (e *Exporter) pageState(i int) PageState {
+	m := e.metas[i]
+	ce := e.caches[i]
+	ce.mu.RLock()
+	res, err, dur, updated, lastKnown, states := ce.res, ce.err, ce.dur, ce.updated, ce.lastKnown, ce.states
+	baselinedAt := ce.baselinedAt
+	ce.mu.RUnlock()
+
+	ps := PageState{
+		Provider:      m.Provider,
+		Page:          m.Page,
+		URL:           m.URL,
+		Labels:        e.pageLabels(i),
+		Incidents:     res.Incidents,
+		OpenIncidents: res.OpenIncidents,
+		Maintenances:  res.Maintenances,
+		UpdatedAt:     pageUpdatedAt(res),
+		FetchedAt:     updated,
+		BaselineAt:    baselinedAt,
+		Duration:      time.Duration(dur * float64(time.Second)),
+		Err:           err,
+	}
+	ps.Components = e.effectiveComponents(i, res, lastKnown)
+	for j := range ps.Components {
+		ps.Components[j].Since = states[componentKey(ps.Components[j].Component)].Since
+	}
+	return ps
+}
+
+// effectiveComponents applies page i's unknown policy and region
+// normalization to the components of res.
+func (e *Exporter) effectiveComponents(i int, res providers.Result, lastKnown map[string]providers.NormalizedStatus) []ComponentState {
+	var out []ComponentState
+	for _, c := range res.Components {
+		st, up, emit := applyUnknownPolicy(e.metas[i].UnknownPolicy, c.Status, lastKnown[componentKey(c)])
+		if !emit {
+			continue
+		}
+		c.Status = st
+		cs := ComponentState{Component: c, Up: up == 1}
+		cs.CanonicalRegion, cs.Continent = e.normalizeRegion(c.Region)
+		out = append(out, cs)
+	}
+	return out
+}
+
+// pageLabels returns the non-empty extra labels of page i.
+func (e *Exporter) pageLabels(i int) map[string]string {
+	out := make(map[string]string)
+	for j, k := range e.labelKeys {
+		if v := e.metas[i].LabelValues[j]; v != "" {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// MatchState reports whether a page state passes the filter.
+func (e *Exporter) MatchState(f Filter, ps PageState) bool {
+	i, ok := e.pageIndex(ps.Provider, ps.Page)
+	return ok && e.MatchPage(f, i)
+}
@@ -3,6 +3,8 @@ package config
 import (
 	"fmt"
 	"os"
+	"regexp"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -10,6 +12,8 @@ import (
 
 type Server struct {
 	Listen string `yaml:"listen"`
+	// Recent change events kept for replay to stream clients. Default: 500
+	EventBuffer int `yaml:"event_buffer"`
 }
 
 type Common struct {
@@ -21,8 +25,53 @@ type Common struct {
 	UserAgent string `yaml:"user_agent"`
 	// Log level: debug|info|warn|error
 	LogLevel string `yaml:"log_level"`
-	// Treat unknown status as up (1) in statuspage_component_up
+	// Treat unknown status as up (1) in statuspage_component_up (default true)
 	UnknownIsUp bool `yaml:"unknown_is_up"`
+	// Default unknown-status policy: up|down|last_known|omit (derived from unknown_is_up when empty)
+	UnknownPolicy string `yaml:"unknown_policy"`
+	// Extra static labels added to every series of every page
+	Labels map[string]string `yaml:"labels"`
+	// Health score tuning
+	Health Health `yaml:"health"`
+	// Default per-page component limit; 0 means unlimited
+	MaxComponents int `yaml:"max_components"`
+	// Limit on the components exported across all pages; 0 means unlimited
+	MaxTotalComponents int `yaml:"max_total_components"`
+	// Prefix of every exported metric name (default "statuspage")
+	MetricPrefix string `yaml:"metric_prefix"`
+	// Values of the status code metrics by normalized status name (default 0-5 scheme)
+	StatusCodes map[string]float64 `yaml:"status_codes"`
+	// Component status exposition: code (default)|stateset|both
+	StatusMetric string `yaml:"status_metric"`
+}
+
+// Health configures the 0-100 health scores.
+type Health struct {
+	// Points subtracted from a component's score by normalized status name
+	// (unknown|operational|under_maintenance|degraded_performance|partial_outage|major_outage)
+	Penalties map[string]float64 `yaml:"penalties"`
+	// Points subtracted from a page's score per open incident (default 5)
+	IncidentPenalty float64 `yaml:"incident_penalty"`
+}
+
+// Default values of the status code metrics per normalized status
+var defaultStatusCodes = map[string]float64{
+	"unknown":              0,
+	"operational":          1,
+	"under_maintenance":    2,
+	"degraded_performance": 3,
+	"partial_outage":       4,
+	"major_outage":         5,
+}
+
+// Default health penalties per normalized status
+var defaultPenalties = map[string]float64{
+	"unknown":              0,
+	"operational":          0,
+	"under_maintenance":    10,
+	"degraded_performance": 30,
+	"partial_outage":       60,
+	"major_outage":         100,
 }
 
 type Page struct {
@@ -48,6 +97,64 @@ type Page struct {
 	// Override intervals per page
 	Interval *time.Duration `yaml:"interval"`
 	Timeout  *time.Duration `yaml:"timeout"`
+
+	// Override unknown-status policy per page: up|down|last_known|omit
+	UnknownPolicy string `yaml:"unknown_policy"`
+
+	// Extra static labels (e.g. team, criticality) added to every series of this page
+	Labels map[string]string `yaml:"labels"`
+
+	// Component filters applied before metrics are emitted
+	Include []ComponentMatcher `yaml:"include"`
+	Exclude []ComponentMatcher `yaml:"exclude"`
+
+	// Relabel rules applied in order to components after filtering
+	Relabel []RelabelRule `yaml:"relabel"`
+
+	// Health score weights; the first matching rule wins, default weight is 1
+	Weights []WeightRule `yaml:"weights"`
+
+	// Override the component limit per page; 0 means unlimited
+	MaxComponents *int `yaml:"max_components"`
+
+	// Per-page incident mirroring routing and threshold
+	IncidentMirror PageMirror `yaml:"incident_mirror"`
+
+	// Per-page overrides for generated Prometheus rules
+	Rules PageRules `yaml:"rules"`
+}
+
+// PageRules overrides rules settings for one page.
+type PageRules struct {
+	Disabled   bool           `yaml:"disabled"`
+	For        *time.Duration `yaml:"for"`
+	StaleAfter *time.Duration `yaml:"stale_after"`
+	SLATarget  *float64       `yaml:"sla_target"`
+	// Alert severity, overriding the criticality label mapping
+	Severity string `yaml:"severity"`
+}
+
+// PageMirror overrides incident_mirror settings for one page.
+type PageMirror struct {
+	Disabled            bool   `yaml:"disabled"`
+	MinSeverity         string `yaml:"min_severity"`
+	PagerDutyRoutingKey string `yaml:"pagerduty_routing_key"`
+	OpsgenieAPIKey      string `yaml:"opsgenie_api_key"`
+}
+
+// WeightRule sets the health score weight of the components it matches.
+type WeightRule struct {
+	ComponentMatcher `yaml:",inline"`
+	Weight           float64 `yaml:"weight"`
+}
+
+// ComponentMatcher selects components; all non-empty fields must match.
+type ComponentMatcher struct {
+	Name   string `yaml:"name"`
+	Group  string `yaml:"group"`
+	Region string `yaml:"region"`
+	// Pattern syntax: glob (default, supports * and ?) or regex; patterns are anchored
+	Match string `yaml:"match"`
 }
 
 type Feed struct {
@@ -56,10 +163,230 @@ type Feed struct {
 	Region  string `yaml:"region"`
 }
 
+// Unknown-status policies
+const (
+	UnknownUp        = "up"
+	UnknownDown      = "down"
+	UnknownLastKnown = "last_known"
+	UnknownOmit      = "omit"
+)
+
+// Label names emitted by the exporter itself; extra labels must not reuse them
+var reservedLabels = map[string]struct{}{
+	"provider": {}, "page": {}, "component": {}, "group": {}, "region": {},
+	"status": {}, "url": {}, "id": {}, "raw_status": {},
+	"canonical_region": {}, "continent": {}, "service": {}, "state": {},
+}
+
+var (
+	labelNameRE  = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+	metricNameRE = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+)
+
+// Component status exposition modes
+const (
+	StatusMetricCode     = "code"
+	StatusMetricStateSet = "stateset"
+	StatusMetricBoth     = "both"
+)
+
+// Notifier body presets
+const (
+	PresetJSON  = "json"
+	PresetSlack = "slack"
+	PresetTeams = "teams"
+)
+
+// Component matcher pattern syntaxes
+const (
+	MatchGlob  = "glob"
+	MatchRegex = "regex"
+)
+
+// RelabelRule rewrites component labels, modeled after Prometheus relabel_config.
+// Available labels are name, group and region.
+type RelabelRule struct {
+	// Labels whose values are joined with Separator and matched against Regex
+	SourceLabels []string `yaml:"source_labels"`
+	// Default: ";"
+	Separator string `yaml:"separator"`
+	// Anchored regular expression; default "(.*)"
+	Regex string `yaml:"regex"`
+	// Label written by replace and set
+	TargetLabel string `yaml:"target_label"`
+	// replace: expanded with regex capture groups (default "$1"); set: literal value
+	Replacement string `yaml:"replacement"`
+	// replace (default)|set|drop|keep
+	Action string `yaml:"action"`
+}
+
+// Relabel actions
+const (
+	RelabelReplace = "replace"
+	RelabelSet     = "set"
+	RelabelDrop    = "drop"
+	RelabelKeep    = "keep"
+)
+
+// Component labels addressable by relabel rules
+var relabelLabels = map[string]struct{}{"name": {}, "group": {}, "region": {}}
+
+// Dependency maps one of our internal services to the vendor components it relies on.
+type Dependency struct {
+	Service    string               `yaml:"service"`
+	Components []DependencySelector `yaml:"components"`
+}
+
+// DependencySelector selects vendor components; all non-empty fields must match.
+// Component/group/region are matched after filters and relabeling.
+type DependencySelector struct {
+	Page      string `yaml:"page"`
+	Component string `yaml:"component"`
+	Group     string `yaml:"group"`
+	Region    string `yaml:"region"`
+	// Pattern syntax: glob (default) or regex; patterns are anchored
+	Match string `yaml:"match"`
+}
+
+// Notifier posts change events (see internal/events) to a webhook.
+type Notifier struct {
+	Name string `yaml:"name"`
+	URL  string `yaml:"url"`
+	// Body format: slack, teams or json (default); ignored when Template is set
+	Preset string `yaml:"preset"`
+	// Go text/template rendering the request body from the event
+	Template string            `yaml:"template"`
+	Headers  map[string]string `yaml:"headers"`
+	// Filters; empty means all. Labels must all match the page labels.
+	Pages       []string          `yaml:"pages"`
+	Labels      map[string]string `yaml:"labels"`
+	Events      []string          `yaml:"events"`
+	MinSeverity string            `yaml:"min_severity"`
+	// Identical events within this window are sent once. Default: 5m
+	DedupWindow time.Duration `yaml:"dedup_window"`
+	// Messages beyond this many per minute are dropped. Default: 30
+	MaxPerMinute int `yaml:"max_per_minute"`
+	// Default: common.timeout
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// Alertmanager pushes vendor status as alerts to Alertmanager's v2 API.
+type Alertmanager struct {
+	// Every Alertmanager of a cluster; empty disables the integration
+	URLs []string `yaml:"urls"`
+	// How often active alerts are re-sent. Default: 1m
+	ResendInterval time.Duration     `yaml:"resend_interval"`
+	Timeout        time.Duration     `yaml:"timeout"`
+	Headers        map[string]string `yaml:"headers"`
+	// Static labels added to every alert
+	Labels map[string]string `yaml:"labels"`
+	// Lowest component status or incident severity that alerts; default:
+	// anything not up
+	MinSeverity string `yaml:"min_severity"`
+}
+
+// IncidentMirror opens incidents in PagerDuty and/or Opsgenie for vendor
+// incidents and resolves them when the vendor does.
+type IncidentMirror struct {
+	// Lowest incident severity that is mirrored. Default: major_outage
+	MinSeverity string        `yaml:"min_severity"`
+	Timeout     time.Duration `yaml:"timeout"`
+	// How often open incidents are reconciled (also on every change). Default: 1m
+	Interval time.Duration `yaml:"interval"`
+	// File keeping the mirrored incidents across restarts; when it cannot be
+	// read they are rebuilt from the journal. Default: incident_mirror.json
+	StatePath string          `yaml:"state_path"`
+	PagerDuty PagerDutyMirror `yaml:"pagerduty"`
+	Opsgenie  OpsgenieMirror  `yaml:"opsgenie"`
+}
+
+type PagerDutyMirror struct {
+	// Default routing key; pages may set their own
+	RoutingKey string `yaml:"routing_key"`
+	// Events API endpoint. Default: https://events.pagerduty.com/v2/enqueue
+	URL string `yaml:"url"`
+	// Event severity: critical, error, warning or info. Default: warning
+	Severity string `yaml:"severity"`
+}
+
+type OpsgenieMirror struct {
+	// Default API key; pages may set their own
+	APIKey string `yaml:"api_key"`
+	// API base URL. Default: https://api.opsgenie.com (EU: https://api.eu.opsgenie.com)
+	URL string `yaml:"url"`
+	// Alert priority P1-P5. Default: P3
+	Priority string `yaml:"priority"`
+}
+
+// Journal records change events for the history API.
+type Journal struct {
+	// JSON lines file the journal is persisted to; in memory only when empty
+	Path string `yaml:"path"`
+	// Events older than this are dropped. Default: 720h (30 days)
+	Retention time.Duration `yaml:"retention"`
+	// Upper bound on kept events, oldest dropped first. Default: 100000
+	MaxEvents int `yaml:"max_events"`
+}
+
+// Rules configures the Prometheus alerting and recording rules printed by
+// the rules subcommand.
+type Rules struct {
+	// Rule group names are <group_prefix>.<page>. Default: statuspage-exporter
+	GroupPrefix string `yaml:"group_prefix"`
+	// How long a component must be down before alerting. Default: 5m
+	For time.Duration `yaml:"for"`
+	// How long scrapes of a page must fail (or its series be absent) before
+	// alerting. Default: 15m
+	ScrapeFailureFor time.Duration `yaml:"scrape_failure_for"`
+	// Alert when the vendor has not updated the page for this long; 0
+	// disables. Default: 0
+	StaleAfter time.Duration `yaml:"stale_after"`
+	// Availability windows of the SLA recording rules. Default: 24h, 168h, 720h
+	SLAWindows []time.Duration `yaml:"sla_windows"`
+	// Alert when availability over the longest window drops below this ratio
+	// (e.g. 0.999); 0 disables
+	SLATarget float64 `yaml:"sla_target"`
+	// Page label whose value picks the alert severity. Default: criticality
+	CriticalityLabel string `yaml:"criticality_label"`
+	// Alert severity by criticality value. Default: critical and high ->
+	// critical, medium -> warning, low -> info
+	Severities map[string]string `yaml:"severities"`
+	// Severity of pages without a criticality label, and of scrape alerts.
+	// Default: warning
+	DefaultSeverity string `yaml:"default_severity"`
+	// Static labels added to every alert
+	Labels map[string]string `yaml:"labels"`
+	// Metadata of the PrometheusRule resource (--format=crd)
+	CRD RulesCRD `yaml:"crd"`
+}
+
+type RulesCRD struct {
+	// Default: statuspage-exporter
+	Name      string            `yaml:"name"`
+	Namespace string            `yaml:"namespace"`
+	Labels    map[string]string `yaml:"labels"`
+}
+
 type Config struct {
-	Server Server `yaml:"server"`
-	Common Common `yaml:"common"`
-	Pages  []Page `yaml:"pages"`
+	Server       Server       `yaml:"server"`
+	Common       Common       `yaml:"common"`
+	Pages        []Page       `yaml:"pages"`
+	Dependencies []Dependency `yaml:"dependencies"`
+	Notifiers    []Notifier   `yaml:"notifiers"`
+	Alertmanager Alertmanager `yaml:"alertmanager"`
+	// Incident mirroring to PagerDuty / Opsgenie
+	IncidentMirror IncidentMirror `yaml:"incident_mirror"`
+	Journal        Journal        `yaml:"journal"`
+	// Generated Prometheus rules (rules subcommand)
+	Rules Rules `yaml:"rules"`
+	// Region normalization overrides keyed by vendor spelling (case-insensitive)
+	Regions map[string]Region `yaml:"regions"`
+}
+
+// Region is the canonical form of a vendor region spelling.
+type Region struct {
+	CanonicalRegion string `yaml:"canonical_region"`
+	Continent       string `yaml:"continent"`
 }
 
 func Load(path string) (*Config, error) {
@@ -67,7 +394,8 @@ func Load(path string) (*Config, error) {
 	if err != nil {
 		return nil, fmt.Errorf("read config: %w", err)
 	}
-	var c Config
+	// Seed defaults that cannot be detected from a zero value after parsing
+	c := Config{Common: Common{UnknownIsUp: true, Health: Health{IncidentPenalty: 5}}}
 	if err := yaml.Unmarshal(b, &c); err != nil {
 		return nil, fmt.Errorf("parse yaml: %w", err)
 	}
@@ -75,6 +403,9 @@ func Load(path string) (*Config, error) {
 	if c.Server.Listen == "" {
 		c.Server.Listen = ":8080"
 	}
+	if c.Server.EventBuffer == 0 {
+		c.Server.EventBuffer = 500
+	}
 	if c.Common.Interval == 0 {
 		c.Common.Interval = 30 * time.Second
 	}
@@ -84,11 +415,372 @@ func Load(path string) (*Config, error) {
 	if c.Common.UserAgent == "" {
 		c.Common.UserAgent = "statuspage-exporter/0.1"
 	}
-	// Default: consider unknown as up to avoid false positives
-	// Users can set unknown_is_up: false to change behavior
-	// Only set default when unset (bool zero is false), so flip to true here
-	if !c.Common.UnknownIsUp {
-		c.Common.UnknownIsUp = true
+	if c.Common.UnknownPolicy == "" {
+		c.Common.UnknownPolicy = UnknownDown
+		if c.Common.UnknownIsUp {
+			c.Common.UnknownPolicy = UnknownUp
+		}
+	}
+	if c.Common.MetricPrefix == "" {
+		c.Common.MetricPrefix = "statuspage"
+	}
+	if c.Common.StatusMetric == "" {
+		c.Common.StatusMetric = StatusMetricCode
+	}
+	if c.Common.StatusCodes == nil {
+		c.Common.StatusCodes = make(map[string]float64, len(defaultStatusCodes))
+	}
+	for k, v := range defaultStatusCodes {
+		if _, ok := c.Common.StatusCodes[k]; !ok {
+			c.Common.StatusCodes[k] = v
+		}
+	}
+	if c.Common.Health.Penalties == nil {
+		c.Common.Health.Penalties = make(map[string]float64, len(defaultPenalties))
+	}
+	for k, v := range defaultPenalties {
+		if _, ok := c.Common.Health.Penalties[k]; !ok {
+			c.Common.Health.Penalties[k] = v
+		}
+	}
+	for i := range c.Notifiers {
+		n := &c.Notifiers[i]
+		if n.Preset == "" {
+			n.Preset = PresetJSON
+		}
+		if n.DedupWindow == 0 {
+			n.DedupWindow = 5 * time.Minute
+		}
+		if n.MaxPerMinute == 0 {
+			n.MaxPerMinute = 30
+		}
+		if n.Timeout == 0 {
+			n.Timeout = c.Common.Timeout
+		}
+	}
+	if c.Alertmanager.ResendInterval == 0 {
+		c.Alertmanager.ResendInterval = time.Minute
+	}
+	if c.Alertmanager.Timeout == 0 {
+		c.Alertmanager.Timeout = c.Common.Timeout
+	}
+	if c.Journal.Retention == 0 {
+		c.Journal.Retention = 30 * 24 * time.Hour
+	}
+	if c.Journal.MaxEvents == 0 {
+		c.Journal.MaxEvents = 100000
+	}
+	r := &c.Rules
+	if r.GroupPrefix == "" {
+		r.GroupPrefix = "statuspage-exporter"
+	}
+	if r.For == 0 {
+		r.For = 5 * time.Minute
+	}
+	if r.ScrapeFailureFor == 0 {
+		r.ScrapeFailureFor = 15 * time.Minute
+	}
+	if len(r.SLAWindows) == 0 {
+		r.SLAWindows = []time.Duration{24 * time.Hour, 7 * 24 * time.Hour, 30 * 24 * time.Hour}
+	}
+	if r.CriticalityLabel == "" {
+		r.CriticalityLabel = "criticality"
+	}
+	if r.Severities == nil {
+		r.Severities = map[string]string{"critical": "critical", "high": "critical", "medium": "warning", "low": "info"}
+	}
+	if r.DefaultSeverity == "" {
+		r.DefaultSeverity = "warning"
+	}
+	if r.CRD.Name == "" {
+		r.CRD.Name = "statuspage-exporter"
+	}
+	im := &c.IncidentMirror
+	if im.MinSeverity == "" {
+		im.MinSeverity = "major_outage"
+	}
+	if im.Timeout == 0 {
+		im.Timeout = c.Common.Timeout
+	}
+	if im.Interval == 0 {
+		im.Interval = time.Minute
+	}
+	if im.StatePath == "" {
+		im.StatePath = "incident_mirror.json"
+	}
+	if im.PagerDuty.URL == "" {
+		im.PagerDuty.URL = "https://events.pagerduty.com/v2/enqueue"
+	}
+	if im.PagerDuty.Severity == "" {
+		im.PagerDuty.Severity = "warning"
+	}
+	if im.Opsgenie.URL == "" {
+		im.Opsgenie.URL = "https://api.opsgenie.com"
+	}
+	if im.Opsgenie.Priority == "" {
+		im.Opsgenie.Priority = "P3"
+	}
+	if err := c.validate(); err != nil {
+		return nil, err
 	}
 	return &c, nil
 }
+
+func (c *Config) validate() error {
+	if c.Server.EventBuffer < 0 {
+		return fmt.Errorf("server: event_buffer must not be negative")
+	}
+	if !validUnknownPolicy(c.Common.UnknownPolicy) {
+		return fmt.Errorf("common: invalid unknown_policy %q (want up|down|last_known|omit)", c.Common.UnknownPolicy)
+	}
+	if err := validateLabels(c.Common.Labels); err != nil {
+		return fmt.Errorf("common: %w", err)
+	}
+	if !metricNameRE.MatchString(c.Common.MetricPrefix) {
+		return fmt.Errorf("common: invalid metric_prefix %q", c.Common.MetricPrefix)
+	}
+	switch c.Common.StatusMetric {
+	case StatusMetricCode, StatusMetricStateSet, StatusMetricBoth:
+	default:
+		return fmt.Errorf("common: invalid status_metric %q (want code|stateset|both)", c.Common.StatusMetric)
+	}
+	for k := range c.Common.StatusCodes {
+		if _, ok := defaultStatusCodes[k]; !ok {
+			return fmt.Errorf("common: unknown status %q in status_codes", k)
+		}
+	}
+	for k, v := range c.Common.Health.Penalties {
+		if _, ok := defaultPenalties[k]; !ok {
+			return fmt.Errorf("common: health: unknown status %q in penalties", k)
+		}
+		if v < 0 || v > 100 {
+			return fmt.Errorf("common: health: penalty for %s must be between 0 and 100", k)
+		}
+	}
+	if c.Common.MaxComponents < 0 {
+		return fmt.Errorf("common: max_components must not be negative")
+	}
+	if c.Common.MaxTotalComponents < 0 {
+		return fmt.Errorf("common: max_total_components must not be negative")
+	}
+	if c.Common.Health.IncidentPenalty < 0 {
+		return fmt.Errorf("common: health: incident_penalty must not be negative")
+	}
+	for _, p := range c.Pages {
+		if p.UnknownPolicy != "" && !validUnknownPolicy(p.UnknownPolicy) {
+			return fmt.Errorf("page %s: invalid unknown_policy %q (want up|down|last_known|omit)", p.Name, p.UnknownPolicy)
+		}
+		if err := validateLabels(p.Labels); err != nil {
+			return fmt.Errorf("page %s: %w", p.Name, err)
+		}
+		if ms := p.IncidentMirror.MinSeverity; ms != "" {
+			if _, ok := defaultStatusCodes[ms]; !ok {
+				return fmt.Errorf("page %s: incident_mirror: invalid min_severity %q", p.Name, ms)
+			}
+		}
+		if pr := p.Rules; (pr.For != nil && *pr.For < 0) || (pr.StaleAfter != nil && *pr.StaleAfter < 0) {
+			return fmt.Errorf("page %s: rules: for and stale_after must not be negative", p.Name)
+		}
+		if t := p.Rules.SLATarget; t != nil && (*t < 0 || *t >= 1) {
+			return fmt.Errorf("page %s: rules: sla_target must be in [0, 1)", p.Name)
+		}
+		if p.MaxComponents != nil && *p.MaxComponents < 0 {
+			return fmt.Errorf("page %s: max_components must not be negative", p.Name)
+		}
+		for _, m := range append(append([]ComponentMatcher{}, p.Include...), p.Exclude...) {
+			if err := m.validate(); err != nil {
+				return fmt.Errorf("page %s: %w", p.Name, err)
+			}
+		}
+		for j, r := range p.Relabel {
+			if err := r.validate(); err != nil {
+				return fmt.Errorf("page %s: relabel[%d]: %w", p.Name, j, err)
+			}
+		}
+		for j, w := range p.Weights {
+			if err := w.validate(); err != nil {
+				return fmt.Errorf("page %s: weights[%d]: %w", p.Name, j, err)
+			}
+			if w.Weight < 0 {
+				return fmt.Errorf("page %s: weights[%d]: weight must not be negative", p.Name, j)
+			}
+		}
+	}
+	services := make(map[string]struct{})
+	for _, d := range c.Dependencies {
+		if d.Service == "" {
+			return fmt.Errorf("dependencies: service name is required")
+		}
+		if _, dup := services[d.Service]; dup {
+			return fmt.Errorf("dependencies: duplicate service %q", d.Service)
+		}
+		services[d.Service] = struct{}{}
+		if len(d.Components) == 0 {
+			return fmt.Errorf("dependency %s: at least one component selector is required", d.Service)
+		}
+		for _, s := range d.Components {
+			if s.Page == "" && s.Component == "" && s.Group == "" && s.Region == "" {
+				return fmt.Errorf("dependency %s: selector needs at least one of page, component, group or region", d.Service)
+			}
+			if s.Match != "" && s.Match != MatchGlob && s.Match != MatchRegex {
+				return fmt.Errorf("dependency %s: invalid match %q (want glob|regex)", d.Service, s.Match)
+			}
+		}
+	}
+	notifiers := make(map[string]struct{})
+	for _, n := range c.Notifiers {
+		if n.Name == "" {
+			return fmt.Errorf("notifiers: name is required")
+		}
+		if _, dup := notifiers[n.Name]; dup {
+			return fmt.Errorf("notifiers: duplicate name %q", n.Name)
+		}
+		notifiers[n.Name] = struct{}{}
+		if err := n.validate(); err != nil {
+			return fmt.Errorf("notifier %s: %w", n.Name, err)
+		}
+	}
+	if err := c.Alertmanager.validate(); err != nil {
+		return fmt.Errorf("alertmanager: %w", err)
+	}
+	if err := c.IncidentMirror.validate(); err != nil {
+		return fmt.Errorf("incident_mirror: %w", err)
+	}
+	if c.Journal.Retention < 0 || c.Journal.MaxEvents < 0 {
+		return fmt.Errorf("journal: retention and max_events must not be negative")
+	}
+	if err := c.Rules.validate(); err != nil {
+		return fmt.Errorf("rules: %w", err)
+	}
+	return nil
+}
+
+func (r Rules) validate() error {
+	if r.For < 0 || r.ScrapeFailureFor < 0 || r.StaleAfter < 0 {
+		return fmt.Errorf("for, scrape_failure_for and stale_after must not be negative")
+	}
+	for _, w := range r.SLAWindows {
+		if w <= 0 {
+			return fmt.Errorf("sla_windows must be positive")
+		}
+	}
+	if r.SLATarget < 0 || r.SLATarget >= 1 {
+		return fmt.Errorf("sla_target must be in [0, 1)")
+	}
+	if !labelNameRE.MatchString(r.CriticalityLabel) {
+		return fmt.Errorf("invalid criticality_label %q", r.CriticalityLabel)
+	}
+	return validateLabels(r.Labels)
+}
+
+func (m IncidentMirror) validate() error {
+	if _, ok := defaultStatusCodes[m.MinSeverity]; !ok {
+		return fmt.Errorf("invalid min_severity %q", m.MinSeverity)
+	}
+	switch m.PagerDuty.Severity {
+	case "critical", "error", "warning", "info":
+	default:
+		return fmt.Errorf("pagerduty: invalid severity %q (want critical|error|warning|info)", m.PagerDuty.Severity)
+	}
+	switch m.Opsgenie.Priority {
+	case "P1", "P2", "P3", "P4", "P5":
+	default:
+		return fmt.Errorf("opsgenie: invalid priority %q (want P1-P5)", m.Opsgenie.Priority)
+	}
+	if m.Timeout < 0 || m.Interval < 0 {
+		return fmt.Errorf("timeout and interval must not be negative")
+	}
+	return nil
+}
+
+func (a Alertmanager) validate() error {
+	for _, u := range a.URLs {
+		if !strings.HasPrefix(u, "http://") && !strings.HasPrefix(u, "https://") {
+			return fmt.Errorf("url must be http(s), got %q", u)
+		}
+	}
+	if err := validateLabels(a.Labels); err != nil {
+		return err
+	}
+	if _, ok := defaultStatusCodes[a.MinSeverity]; a.MinSeverity != "" && !ok {
+		return fmt.Errorf("invalid min_severity %q", a.MinSeverity)
+	}
+	if a.ResendInterval < 0 || a.Timeout < 0 {
+		return fmt.Errorf("resend_interval and timeout must not be negative")
+	}
+	return nil
+}
+
+func (n Notifier) validate() error {
+	if !strings.HasPrefix(n.URL, "http://") && !strings.HasPrefix(n.URL, "https://") {
+		return fmt.Errorf("url must be http(s), got %q", n.URL)
+	}
+	switch n.Preset {
+	case PresetJSON, PresetSlack, PresetTeams:
+	default:
+		return fmt.Errorf("invalid preset %q (want json|slack|teams)", n.Preset)
+	}
+	if _, ok := defaultStatusCodes[n.MinSeverity]; n.MinSeverity != "" && !ok {
+		return fmt.Errorf("invalid min_severity %q", n.MinSeverity)
+	}
+	if n.DedupWindow < 0 || n.MaxPerMinute < 0 || n.Timeout < 0 {
+		return fmt.Errorf("dedup_window, max_per_minute and timeout must not be negative")
+	}
+	return nil
+}
+
+func (r RelabelRule) validate() error {
+	for _, l := range r.SourceLabels {
+		if _, ok := relabelLabels[l]; !ok {
+			return fmt.Errorf("invalid source label %q (want name|group|region)", l)
+		}
+	}
+	switch r.Action {
+	case "", RelabelReplace, RelabelSet:
+		if _, ok := relabelLabels[r.TargetLabel]; !ok {
+			return fmt.Errorf("invalid target_label %q (want name|group|region)", r.TargetLabel)
+		}
+		if r.Action != RelabelSet && len(r.SourceLabels) == 0 {
+			return fmt.Errorf("replace requires source_labels")
+		}
+	case RelabelDrop, RelabelKeep:
+		if len(r.SourceLabels) == 0 {
+			return fmt.Errorf("%s requires source_labels", r.Action)
+		}
+	default:
+		return fmt.Errorf("invalid action %q (want replace|set|drop|keep)", r.Action)
+	}
+	return nil
+}
+
+func (m ComponentMatcher) validate() error {
+	switch m.Match {
+	case "", MatchGlob, MatchRegex:
+	default:
+		return fmt.Errorf("invalid match %q (want glob|regex)", m.Match)
+	}
+	if m.Name == "" && m.Group == "" && m.Region == "" {
+		return fmt.Errorf("component matcher needs at least one of name, group or region")
+	}
+	return nil
+}
+
+func validateLabels(labels map[string]string) error {
+	for k := range labels {
+		if !labelNameRE.MatchString(k) || strings.HasPrefix(k, "__") {
+			return fmt.Errorf("invalid label name %q", k)
+		}
+		if _, ok := reservedLabels[k]; ok {
+			return fmt.Errorf("label %q collides with a built-in label", k)
+		}
+	}
+	return nil
+}
+
+func validUnknownPolicy(s string) bool {
+	switch s {
+	case UnknownUp, UnknownDown, UnknownLastKnown, UnknownOmit:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,186 @@
+// Package events carries vendor status changes detected by the collector to
+// in-process consumers (event stream, notifiers, journal).
+package events
+
+import (
+	"sync"
+	"time"
+
+	"github.com/conradoqg/statuspage-exporter/internal/providers"
+)
+
+type Type string
+
+const (
+	ComponentStatusChanged Type = "component_status_changed"
+	IncidentOpened         Type = "incident_opened"
+	IncidentResolved       Type = "incident_resolved"
+	PageFetchFailed        Type = "page_fetch_failed"
+	PageFetchRecovered     Type = "page_fetch_recovered"
+	MaintenanceScheduled   Type = "maintenance_scheduled"
+	MaintenanceCompleted   Type = "maintenance_completed"
+)
+
+// Event is a single change on a page. Field names are part of the public API.
+type Event struct {
+	// Monotonic per process, assigned by Bus.Publish
+	ID       uint64            `json:"id"`
+	Type     Type              `json:"type"`
+	Time     time.Time         `json:"time"`
+	Provider string            `json:"provider"`
+	Page     string            `json:"page"`
+	URL      string            `json:"url,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	// Component events
+	Component string `json:"component,omitempty"`
+	Group     string `json:"group,omitempty"`
+	Region    string `json:"region,omitempty"`
+	From      string `json:"from,omitempty"`
+	To        string `json:"to,omitempty"`
+	// Most severe status involved: the worse of from/to, or the incident
+	// severity. Unknown for fetch events.
+	Severity providers.NormalizedStatus `json:"severity"`
+	// Time spent in the previous state: the old component status, the
+	// incident's lifetime or the failing period
+	DurationSeconds float64   `json:"duration_seconds,omitempty"`
+	Incident        *Incident `json:"incident,omitempty"`
+	Error           string    `json:"error,omitempty"`
+}
+
+type Incident struct {
+	ID        string    `json:"id,omitempty"`
+	Name      string    `json:"name"`
+	Status    string    `json:"status,omitempty"`
+	Impact    string    `json:"impact,omitempty"`
+	Severity  string    `json:"severity"`
+	URL       string    `json:"url,omitempty"`
+	StartedAt time.Time `json:"started_at,omitempty"`
+	// Scheduled end, for maintenances
+	EndsAt time.Time `json:"ends_at,omitempty"`
+}
+
+func NewIncident(inc providers.Incident) *Incident {
+	return &Incident{
+		ID:        inc.ID,
+		Name:      inc.Name,
+		Status:    inc.Status,
+		Impact:    inc.Impact,
+		Severity:  inc.Severity.String(),
+		URL:       inc.URL,
+		StartedAt: inc.StartedAt,
+		EndsAt:    inc.EndsAt,
+	}
+}
+
+// Bus fans events out to subscribers and keeps the most recent ones for replay.
+type Bus struct {
+	mu       sync.Mutex
+	last     uint64
+	ring     []Event
+	size     int
+	subs     map[chan Event]struct{}
+	handlers []func(Event)
+	// Events not delivered to a full subscriber channel
+	dropped uint64
+}
+
+func NewBus(size int) *Bus {
+	return &Bus{size: size, subs: make(map[chan Event]struct{})}
+}
+
+// Resume makes the next published ID follow id, so IDs stay unique across
+// restarts when earlier events were persisted.
+func (b *Bus) Resume(id uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if id > b.last {
+		b.last = id
+	}
+}
+
+// Handle registers fn to receive every event synchronously, in publish
+// order, so consumers that must not miss events (journal, notifiers) are not
+// subject to subscriber drops. fn must not block for long nor publish.
+func (b *Bus) Handle(fn func(Event)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers = append(b.handlers, fn)
+}
+
+// Dropped returns how many events were not delivered to full subscriber
+// channels.
+func (b *Bus) Dropped() uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.dropped
+}
+
+// Publish assigns the next ID (and the current time when unset), runs the
+// handlers and delivers ev to every subscriber. Slow subscribers miss events
+// rather than block; see Dropped.
+func (b *Bus) Publish(ev Event) Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.last++
+	ev.ID = b.last
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+	if b.size > 0 {
+		if len(b.ring) == b.size {
+			copy(b.ring, b.ring[1:])
+			b.ring = b.ring[:b.size-1]
+		}
+		b.ring = append(b.ring, ev)
+	}
+	for _, fn := range b.handlers {
+		fn(ev)
+	}
+	for ch := range b.subs {
+		select {
+		case ch <- ev:
+		default:
+			b.dropped++
+		}
+	}
+	return ev
+}
+
+// Subscribe returns the buffered events newer than afterID and a channel for
+// subsequent ones. An afterID from the future (e.g. issued before a restart)
+// replays the whole buffer. cancel must be called to release the channel.
+func (b *Bus) Subscribe(afterID uint64) (replay []Event, ch <-chan Event, cancel func()) {
+	c := make(chan Event, 64)
+	b.mu.Lock()
+	if afterID > b.last {
+		afterID = 0
+	}
+	replay = b.since(afterID)
+	b.subs[c] = struct{}{}
+	b.mu.Unlock()
+	var once sync.Once
+	return replay, c, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subs, c)
+			b.mu.Unlock()
+		})
+	}
+}
+
+// Recent returns the buffered events newer than afterID, oldest first.
+func (b *Bus) Recent(afterID uint64) []Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.since(afterID)
+}
+
+func (b *Bus) since(afterID uint64) []Event {
+	var out []Event
+	for _, ev := range b.ring {
+		if ev.ID > afterID {
+			out = append(out, ev)
+		}
+	}
+	return out
+}
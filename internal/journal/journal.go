@@ -0,0 +1,271 @@
+// Package journal keeps an append-only history of change events, bounded by
+// retention, optionally persisted as JSON lines.
+package journal
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/conradoqg/statuspage-exporter/internal/config"
+	"github.com/conradoqg/statuspage-exporter/internal/events"
+	"github.com/conradoqg/statuspage-exporter/internal/logx"
+)
+
+// How often the journal file is rewritten without expired events
+const compactInterval = time.Hour
+
+type Journal struct {
+	mu        sync.RWMutex
+	entries   []events.Event
+	retention time.Duration
+	maxEvents int
+
+	path string
+	// Serializes file writes and compaction; taken before mu
+	wmu  sync.Mutex
+	file *os.File
+	// Encoded events not written yet, guarded by mu
+	pending []byte
+	flush   chan struct{}
+	// Events dropped from memory but still in the file
+	expired int
+}
+
+// Query selects journal entries; empty fields match everything.
+type Query struct {
+	Pages      []string
+	Components []string
+	Types      []string
+	Since      time.Time
+	Until      time.Time
+	// Keep only the most recent Limit matches; 0 means no limit
+	Limit int
+}
+
+// Open loads the journal file (if configured) and opens it for appending.
+func Open(cfg config.Journal) (*Journal, error) {
+	j := &Journal{retention: cfg.Retention, maxEvents: cfg.MaxEvents, path: cfg.Path}
+	if j.path == "" {
+		return j, nil
+	}
+	if err := j.load(); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(j.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open journal: %w", err)
+	}
+	j.file = f
+	logx.Infof("journal: loaded %d events from %s", len(j.entries), j.path)
+	if err := j.compact(); err != nil {
+		logx.Warnf("journal: compact %s: %v", j.path, err)
+	}
+	j.flush = make(chan struct{}, 1)
+	go j.writeLoop()
+	return j, nil
+}
+
+func (j *Journal) load() error {
+	f, err := os.Open(j.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("open journal: %w", err)
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64<<10), 1<<20)
+	line := 0
+	for sc.Scan() {
+		line++
+		var ev events.Event
+		if err := json.Unmarshal(sc.Bytes(), &ev); err != nil {
+			// A torn last line after a crash should not block startup
+			logx.Warnf("journal: skipping line %d of %s: %v", line, j.path, err)
+			continue
+		}
+		j.entries = append(j.entries, ev)
+	}
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("read journal: %w", err)
+	}
+	j.expire(time.Now())
+	return nil
+}
+
+// LastID returns the highest recorded event ID.
+func (j *Journal) LastID() uint64 {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	var id uint64
+	for _, ev := range j.entries {
+		if ev.ID > id {
+			id = ev.ID
+		}
+	}
+	return id
+}
+
+// Run records every event published on bus and compacts the file periodically.
+func (j *Journal) Run(bus *events.Bus) {
+	bus.Handle(j.Append)
+	if j.file != nil {
+		go func() {
+			for range time.Tick(compactInterval) {
+				if err := j.compact(); err != nil {
+					logx.Warnf("journal: compact %s: %v", j.path, err)
+				}
+			}
+		}()
+	}
+}
+
+// Append records ev. It runs on the bus's publish path, so the file is
+// written by a separate goroutine.
+func (j *Journal) Append(ev events.Event) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.entries = append(j.entries, ev)
+	j.expire(time.Now())
+	if j.flush == nil {
+		return
+	}
+	b, err := json.Marshal(ev)
+	if err != nil {
+		logx.Warnf("journal: encode event %d: %v", ev.ID, err)
+		return
+	}
+	j.pending = append(append(j.pending, b...), '\n')
+	select {
+	case j.flush <- struct{}{}:
+	default:
+	}
+}
+
+// writeLoop appends pending events to the file in batches.
+func (j *Journal) writeLoop() {
+	for range j.flush {
+		j.wmu.Lock()
+		j.mu.Lock()
+		b := j.pending
+		j.pending = nil
+		j.mu.Unlock()
+		if len(b) > 0 {
+			if _, err := j.file.Write(b); err != nil {
+				logx.Warnf("journal: write %s: %v", j.path, err)
+			}
+		}
+		j.wmu.Unlock()
+	}
+}
+
+// expire drops entries past retention or beyond the size bound. Entries are
+// appended in time order, so expired ones are always at the front. The slice
+// is resliced rather than copied: append reallocates it from time to time,
+// which keeps trimming amortized O(1) per event.
+func (j *Journal) expire(now time.Time) {
+	n := 0
+	for n < len(j.entries) && now.Sub(j.entries[n].Time) > j.retention {
+		n++
+	}
+	if over := len(j.entries) - n - j.maxEvents; over > 0 {
+		n += over
+	}
+	if n == 0 {
+		return
+	}
+	clear(j.entries[:n])
+	j.entries = j.entries[n:]
+	j.expired += n
+}
+
+// compact rewrites the file with the retained entries only, which include
+// the pending ones.
+func (j *Journal) compact() error {
+	j.wmu.Lock()
+	defer j.wmu.Unlock()
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.expire(time.Now())
+	if j.expired == 0 {
+		return nil
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(j.path), filepath.Base(j.path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	w := bufio.NewWriter(tmp)
+	enc := json.NewEncoder(w)
+	for _, ev := range j.entries {
+		if err = enc.Encode(ev); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), j.path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	f, err := os.OpenFile(j.path, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("reopen journal: %w", err)
+	}
+	j.file.Close()
+	j.file = f
+	j.pending = nil
+	logx.Debugf("journal: compacted %s, dropped %d expired events", j.path, j.expired)
+	j.expired = 0
+	return nil
+}
+
+// Query returns matching entries, oldest first.
+func (j *Journal) Query(q Query) []events.Event {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	out := make([]events.Event, 0)
+	for _, ev := range j.entries {
+		if q.match(ev) {
+			out = append(out, ev)
+		}
+	}
+	if q.Limit > 0 && len(out) > q.Limit {
+		out = out[len(out)-q.Limit:]
+	}
+	return out
+}
+
+func (q Query) match(ev events.Event) bool {
+	if !q.Since.IsZero() && ev.Time.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && ev.Time.After(q.Until) {
+		return false
+	}
+	return matchAny(q.Pages, ev.Page) && matchAny(q.Components, ev.Component) && matchAny(q.Types, string(ev.Type))
+}
+
+func matchAny(list []string, v string) bool {
+	if len(list) == 0 {
+		return true
+	}
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,157 @@
+package journal
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/conradoqg/statuspage-exporter/internal/config"
+	"github.com/conradoqg/statuspage-exporter/internal/events"
+)
+
+func ids(evs []events.Event) []uint64 {
+	out := make([]uint64, 0, len(evs))
+	for _, ev := range evs {
+		out = append(out, ev.ID)
+	}
+	return out
+}
+
+func TestQuery(t *testing.T) {
+	t0 := time.Now().Add(-time.Hour)
+	j, err := Open(config.Journal{Retention: 24 * time.Hour, MaxEvents: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for n, ev := range []events.Event{
+		{Type: events.ComponentStatusChanged, Page: "github", Component: "API"},
+		{Type: events.IncidentOpened, Page: "github"},
+		{Type: events.ComponentStatusChanged, Page: "stripe", Component: "API"},
+		{Type: events.PageFetchFailed, Page: "stripe"},
+		{Type: events.ComponentStatusChanged, Page: "github", Component: "Actions"},
+	} {
+		ev.ID = uint64(n + 1)
+		ev.Time = t0.Add(time.Duration(n) * time.Minute)
+		j.Append(ev)
+	}
+	tests := []struct {
+		name string
+		q    Query
+		want []uint64
+	}{
+		{"everything", Query{}, []uint64{1, 2, 3, 4, 5}},
+		{"page", Query{Pages: []string{"stripe"}}, []uint64{3, 4}},
+		{"pages", Query{Pages: []string{"stripe", "github"}}, []uint64{1, 2, 3, 4, 5}},
+		{"component", Query{Components: []string{"API"}}, []uint64{1, 3}},
+		{"type", Query{Types: []string{string(events.ComponentStatusChanged)}}, []uint64{1, 3, 5}},
+		{"all filters must match", Query{Pages: []string{"github"}, Components: []string{"API"}}, []uint64{1}},
+		{"since and until are inclusive", Query{Since: t0.Add(time.Minute), Until: t0.Add(3 * time.Minute)}, []uint64{2, 3, 4}},
+		{"limit keeps the most recent", Query{Pages: []string{"github"}, Limit: 2}, []uint64{2, 5}},
+		{"no match", Query{Pages: []string{"slack"}}, []uint64{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ids(j.Query(tt.q)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if got := j.LastID(); got != 5 {
+		t.Errorf("LastID = %d, want 5", got)
+	}
+}
+
+func TestExpire(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name      string
+		retention time.Duration
+		maxEvents int
+		want      []uint64
+	}{
+		{"within bounds", 24 * time.Hour, 10, []uint64{1, 2, 3, 4}},
+		{"retention", 90 * time.Minute, 10, []uint64{3, 4}},
+		{"max events", 24 * time.Hour, 3, []uint64{2, 3, 4}},
+		{"both", 150 * time.Minute, 1, []uint64{4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			j, err := Open(config.Journal{Retention: tt.retention, MaxEvents: tt.maxEvents})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for n := 1; n <= 4; n++ {
+				j.Append(events.Event{ID: uint64(n), Time: now.Add(time.Duration(n-4) * time.Hour)})
+			}
+			if got := ids(j.Query(Query{})); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// waitForLines waits for the background writer to persist n lines.
+func waitForLines(t *testing.T, path string, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		b, _ := os.ReadFile(path)
+		if bytes.Count(b, []byte("\n")) >= n {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%s has %d lines, want %d", path, bytes.Count(b, []byte("\n")), n)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	cfg := config.Journal{Path: path, Retention: 24 * time.Hour, MaxEvents: 2}
+	j, err := Open(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	for n := 1; n <= 3; n++ {
+		j.Append(events.Event{ID: uint64(n), Type: events.IncidentOpened, Page: "github", Time: now})
+	}
+	waitForLines(t, path, 3)
+
+	// Reopening loads the file and compacts away the event over max_events
+	j, err = Open(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(j.Query(Query{})); !reflect.DeepEqual(got, []uint64{2, 3}) {
+		t.Errorf("loaded %v, want [2 3]", got)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := bytes.Count(b, []byte("\n")); n != 2 {
+		t.Errorf("compacted file has %d lines, want 2", n)
+	}
+	j.Append(events.Event{ID: 4, Page: "github", Time: now})
+	waitForLines(t, path, 3)
+}
+
+func TestLoadSkipsTornLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	data := `{"id":1,"type":"incident_opened","time":"` + time.Now().Format(time.RFC3339) + `","provider":"statuspage","page":"github","severity":"major_outage"}` + "\n" + `{"id":2,"ty`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	j, err := Open(config.Journal{Path: path, Retention: time.Hour, MaxEvents: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(j.Query(Query{})); !reflect.DeepEqual(got, []uint64{1}) {
+		t.Errorf("loaded %v, want [1]", got)
+	}
+}
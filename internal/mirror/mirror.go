@@ -0,0 +1,311 @@
+// Package mirror opens PagerDuty / Opsgenie incidents for vendor incidents and
+// resolves them when the vendor resolves.
+package mirror
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/conradoqg/statuspage-exporter/internal/collector"
+	"github.com/conradoqg/statuspage-exporter/internal/config"
+	"github.com/conradoqg/statuspage-exporter/internal/events"
+	"github.com/conradoqg/statuspage-exporter/internal/journal"
+	"github.com/conradoqg/statuspage-exporter/internal/logx"
+	"github.com/conradoqg/statuspage-exporter/internal/providers"
+)
+
+// target is one incident management service.
+type target interface {
+	name() string
+	trigger(key string, r route, ps collector.PageState, inc providers.Incident) error
+	resolve(key string, r route) error
+}
+
+// route is the resolved mirroring setup of one page.
+type route struct {
+	minSeverity    providers.NormalizedStatus
+	pagerDutyKey   string
+	opsgenieAPIKey string
+}
+
+// mirrored is an incident opened on a target.
+type mirrored struct {
+	Page     string                     `json:"page"`
+	Severity providers.NormalizedStatus `json:"severity"`
+}
+
+type Mirror struct {
+	targets []target
+	// Pages being mirrored
+	routes map[string]route
+	// Keys of every configured page, mirrored or not, and the defaults:
+	// used to resolve incidents that are no longer wanted
+	keys     map[string]route
+	defaults route
+	interval time.Duration
+	// target name -> dedup key -> incident
+	open      map[string]map[string]mirrored
+	statePath string
+	requests  *prometheus.CounterVec
+}
+
+// New returns nil when no PagerDuty routing key or Opsgenie API key is
+// configured, not even for disabled pages.
+func New(cfg *config.Config, namespace string) *Mirror {
+	im := cfg.IncidentMirror
+	defSeverity, _ := providers.ParseStatus(im.MinSeverity)
+	m := &Mirror{
+		routes:    make(map[string]route),
+		keys:      make(map[string]route),
+		defaults:  route{minSeverity: defSeverity, pagerDutyKey: im.PagerDuty.RoutingKey, opsgenieAPIKey: im.Opsgenie.APIKey},
+		interval:  im.Interval,
+		open:      make(map[string]map[string]mirrored),
+		statePath: im.StatePath,
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "incident_mirror_requests_total",
+			Help:      "Incident mirroring requests by target (pagerduty, opsgenie), action (trigger, resolve) and outcome (sent, failed)",
+		}, []string{"target", "action", "outcome"}),
+	}
+	usePD, useOG := m.defaults.pagerDutyKey != "", m.defaults.opsgenieAPIKey != ""
+	for _, p := range cfg.Pages {
+		pm := p.IncidentMirror
+		r := m.defaults
+		if pm.MinSeverity != "" {
+			r.minSeverity, _ = providers.ParseStatus(pm.MinSeverity)
+		}
+		if pm.PagerDutyRoutingKey != "" {
+			r.pagerDutyKey = pm.PagerDutyRoutingKey
+		}
+		if pm.OpsgenieAPIKey != "" {
+			r.opsgenieAPIKey = pm.OpsgenieAPIKey
+		}
+		if r.pagerDutyKey == "" && r.opsgenieAPIKey == "" {
+			continue
+		}
+		usePD = usePD || r.pagerDutyKey != ""
+		useOG = useOG || r.opsgenieAPIKey != ""
+		m.keys[p.Name] = r
+		if !pm.Disabled {
+			m.routes[p.Name] = r
+		}
+	}
+	if !usePD && !useOG {
+		return nil
+	}
+	client := &http.Client{Timeout: im.Timeout}
+	if usePD {
+		m.targets = append(m.targets, &pagerDuty{url: im.PagerDuty.URL, severity: im.PagerDuty.Severity, client: client, userAgent: cfg.Common.UserAgent})
+	}
+	if useOG {
+		m.targets = append(m.targets, &opsgenie{url: im.Opsgenie.URL, priority: im.Opsgenie.Priority, client: client, userAgent: cfg.Common.UserAgent})
+	}
+	for _, t := range m.targets {
+		m.open[t.name()] = make(map[string]mirrored)
+	}
+	return m
+}
+
+// Collector exposes the request counters.
+func (m *Mirror) Collector() prometheus.Collector {
+	return m.requests
+}
+
+// Run reconciles on every interval and whenever the exporter detects a change.
+func (m *Mirror) Run(exp *collector.Exporter) {
+	pages := make([]string, 0, len(m.routes))
+	for p := range m.routes {
+		pages = append(pages, p)
+	}
+	sort.Strings(pages)
+	logx.Infof("incident mirror: mirroring incidents of %v", pages)
+	_, changes, _ := exp.Events().Subscribe(0)
+	go func() {
+		<-exp.Ready()
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+		for {
+			m.reconcile(exp.Snapshot())
+			select {
+			case <-ticker.C:
+			case <-changes:
+				time.Sleep(time.Second)
+				for len(changes) > 0 {
+					<-changes
+				}
+			}
+		}
+	}()
+}
+
+// reconcile triggers vendor incidents at or above the page threshold that
+// are new or got more severe, and resolves mirrored incidents the vendor no
+// longer reports or that are no longer mirrored. Failed requests are retried
+// on the next pass.
+func (m *Mirror) reconcile(pages []collector.PageState) {
+	changed := false
+	defer func() {
+		if changed {
+			m.save()
+		}
+	}()
+	for _, t := range m.targets {
+		for key, mi := range m.open[t.name()] {
+			if r, ok := m.routes[mi.Page]; ok && r.has(t) {
+				continue
+			}
+			r, ok := m.keys[mi.Page]
+			if !ok || !r.has(t) {
+				r = m.defaults
+			}
+			if !r.has(t) {
+				logx.Warnf("incident mirror: no %s key left to resolve %s; forgetting it", t.name(), key)
+				delete(m.open[t.name()], key)
+				changed = true
+				continue
+			}
+			if m.do(t, "resolve", key, t.resolve(key, r)) {
+				delete(m.open[t.name()], key)
+				changed = true
+			}
+		}
+	}
+	for _, ps := range pages {
+		r, ok := m.routes[ps.Page]
+		if !ok || ps.Err != nil || ps.FetchedAt.IsZero() {
+			continue
+		}
+		current := make(map[string]struct{}, len(ps.Incidents))
+		for _, inc := range ps.Incidents {
+			key := dedupKey(ps.Page, inc)
+			current[key] = struct{}{}
+			for _, t := range m.targets {
+				if !r.has(t) {
+					continue
+				}
+				prev, sent := m.open[t.name()][key]
+				if inc.Severity < r.minSeverity || (sent && inc.Severity <= prev.Severity) {
+					continue
+				}
+				if m.do(t, "trigger", key, t.trigger(key, r, ps, inc)) {
+					m.open[t.name()][key] = mirrored{Page: ps.Page, Severity: inc.Severity}
+					changed = true
+				}
+			}
+		}
+		for _, t := range m.targets {
+			for key, mi := range m.open[t.name()] {
+				if _, ok := current[key]; ok || mi.Page != ps.Page {
+					continue
+				}
+				if m.do(t, "resolve", key, t.resolve(key, r)) {
+					delete(m.open[t.name()], key)
+					changed = true
+				}
+			}
+		}
+	}
+}
+
+// Restore loads the incidents mirrored by a previous run, so that those the
+// vendor resolved while the exporter was down get resolved too. Without a
+// state file they are rebuilt from the journal: incidents opened and not
+// resolved since, at or above the page threshold.
+func (m *Mirror) Restore(j *journal.Journal) {
+	if m.statePath != "" {
+		b, err := os.ReadFile(m.statePath)
+		if err == nil {
+			var open map[string]map[string]mirrored
+			if err = json.Unmarshal(b, &open); err == nil {
+				for _, t := range m.targets {
+					for key, mi := range open[t.name()] {
+						m.open[t.name()][key] = mi
+					}
+				}
+				logx.Infof("incident mirror: restored state from %s", m.statePath)
+				return
+			}
+		}
+		if !os.IsNotExist(err) {
+			logx.Warnf("incident mirror: read %s: %v", m.statePath, err)
+		}
+	}
+	open := make(map[string]mirrored)
+	for _, ev := range j.Query(journal.Query{Types: []string{string(events.IncidentOpened), string(events.IncidentResolved)}}) {
+		if ev.Incident == nil {
+			continue
+		}
+		inc := providers.Incident{ID: ev.Incident.ID, Name: ev.Incident.Name}
+		key := dedupKey(ev.Page, inc)
+		if ev.Type == events.IncidentResolved {
+			delete(open, key)
+			continue
+		}
+		open[key] = mirrored{Page: ev.Page, Severity: ev.Severity}
+	}
+	n := 0
+	for key, mi := range open {
+		r, ok := m.routes[mi.Page]
+		if !ok || mi.Severity < r.minSeverity {
+			continue
+		}
+		for _, t := range m.targets {
+			if r.has(t) {
+				m.open[t.name()][key] = mi
+				n++
+			}
+		}
+	}
+	if n > 0 {
+		logx.Infof("incident mirror: restored %d mirrored incidents from the journal", n)
+	}
+}
+
+// save writes the mirrored incidents to the state file, if configured.
+func (m *Mirror) save() {
+	if m.statePath == "" {
+		return
+	}
+	b, err := json.Marshal(m.open)
+	if err == nil {
+		tmp := m.statePath + ".tmp"
+		if err = os.WriteFile(tmp, b, 0o644); err == nil {
+			err = os.Rename(tmp, m.statePath)
+		}
+	}
+	if err != nil {
+		logx.Warnf("incident mirror: write %s: %v", m.statePath, err)
+	}
+}
+
+func (m *Mirror) do(t target, action, key string, err error) bool {
+	if err != nil {
+		m.requests.WithLabelValues(t.name(), action, "failed").Inc()
+		logx.Warnf("incident mirror: %s %s %s: %v", t.name(), action, key, err)
+		return false
+	}
+	m.requests.WithLabelValues(t.name(), action, "sent").Inc()
+	logx.Infof("incident mirror: %s %s %s", t.name(), action, key)
+	return true
+}
+
+func (r route) has(t target) bool {
+	switch t.(type) {
+	case *pagerDuty:
+		return r.pagerDutyKey != ""
+	case *opsgenie:
+		return r.opsgenieAPIKey != ""
+	}
+	return false
+}
+
+// dedupKey identifies a vendor incident across restarts; vendor IDs are
+// only unique per page.
+func dedupKey(page string, inc providers.Incident) string {
+	return "statuspage-exporter:" + page + ":" + collector.IncidentKey(inc.ID, inc.Name)
+}
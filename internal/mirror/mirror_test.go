@@ -0,0 +1,247 @@
+package mirror
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/conradoqg/statuspage-exporter/internal/collector"
+	"github.com/conradoqg/statuspage-exporter/internal/config"
+	"github.com/conradoqg/statuspage-exporter/internal/events"
+	"github.com/conradoqg/statuspage-exporter/internal/journal"
+	"github.com/conradoqg/statuspage-exporter/internal/providers"
+)
+
+// fakePagerDuty records Events API calls as "action key routing_key".
+type fakePagerDuty struct {
+	mu    sync.Mutex
+	calls []string
+	fail  bool
+}
+
+func (f *fakePagerDuty) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var ev pdEvent
+	if err := json.NewDecoder(r.Body).Decode(&ev); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.fail {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	f.calls = append(f.calls, ev.EventAction+" "+ev.DedupKey+" "+ev.RoutingKey)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (f *fakePagerDuty) take() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	out := f.calls
+	f.calls = nil
+	return out
+}
+
+func newTestMirror(t *testing.T, url string, pages ...config.Page) *Mirror {
+	t.Helper()
+	cfg := &config.Config{
+		Pages: pages,
+		IncidentMirror: config.IncidentMirror{
+			MinSeverity: "major_outage",
+			Timeout:     time.Second,
+			Interval:    time.Minute,
+			StatePath:   filepath.Join(t.TempDir(), "incident_mirror.json"),
+			PagerDuty:   config.PagerDutyMirror{RoutingKey: "DEFAULT", URL: url, Severity: "warning"},
+		},
+	}
+	m := New(cfg, "test")
+	if m == nil {
+		t.Fatal("New returned nil")
+	}
+	return m
+}
+
+func pageState(page string, incs ...providers.Incident) collector.PageState {
+	return collector.PageState{Provider: "statuspage", Page: page, Incidents: incs, FetchedAt: time.Now()}
+}
+
+func TestReconcile(t *testing.T) {
+	outage := providers.Incident{ID: "inc1", Name: "API down", Severity: providers.StatusMajorOutage}
+	minor := providers.Incident{ID: "inc2", Name: "Slow builds", Severity: providers.StatusDegraded}
+	escalated := minor
+	escalated.Severity = providers.StatusMajorOutage
+	failing := pageState("github")
+	failing.Err = errors.New("fetch failed")
+
+	type step struct {
+		pages []collector.PageState
+		fail  bool
+		want  []string
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "trigger once and resolve",
+			steps: []step{
+				{pages: []collector.PageState{pageState("github", outage)}, want: []string{"trigger statuspage-exporter:github:inc1 DEFAULT"}},
+				{pages: []collector.PageState{pageState("github", outage)}},
+				{pages: []collector.PageState{pageState("github")}, want: []string{"resolve statuspage-exporter:github:inc1 DEFAULT"}},
+				{pages: []collector.PageState{pageState("github")}},
+			},
+		},
+		{
+			name: "below the threshold until it escalates",
+			steps: []step{
+				{pages: []collector.PageState{pageState("github", minor)}},
+				{pages: []collector.PageState{pageState("github", escalated)}, want: []string{"trigger statuspage-exporter:github:inc2 DEFAULT"}},
+			},
+		},
+		{
+			name: "page overrides",
+			steps: []step{
+				{pages: []collector.PageState{pageState("stripe", minor)}, want: []string{"trigger statuspage-exporter:stripe:inc2 STRIPE"}},
+			},
+		},
+		{
+			name: "failing pages are left alone",
+			steps: []step{
+				{pages: []collector.PageState{pageState("github", outage)}, want: []string{"trigger statuspage-exporter:github:inc1 DEFAULT"}},
+				{pages: []collector.PageState{failing}},
+			},
+		},
+		{
+			name: "failed requests are retried",
+			steps: []step{
+				{pages: []collector.PageState{pageState("github", outage)}, fail: true},
+				{pages: []collector.PageState{pageState("github", outage)}, want: []string{"trigger statuspage-exporter:github:inc1 DEFAULT"}},
+			},
+		},
+		{
+			name: "pages without a route are ignored",
+			steps: []step{
+				{pages: []collector.PageState{pageState("slack", outage)}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pd := &fakePagerDuty{}
+			srv := httptest.NewServer(pd)
+			defer srv.Close()
+			m := newTestMirror(t, srv.URL,
+				config.Page{Name: "github"},
+				config.Page{Name: "stripe", IncidentMirror: config.PageMirror{PagerDutyRoutingKey: "STRIPE", MinSeverity: "degraded_performance"}},
+				config.Page{Name: "slack", IncidentMirror: config.PageMirror{Disabled: true}},
+			)
+			for n, s := range tt.steps {
+				pd.fail = s.fail
+				m.reconcile(s.pages)
+				if got := pd.take(); !reflect.DeepEqual(got, s.want) {
+					t.Fatalf("step %d: got %q, want %q", n, got, s.want)
+				}
+			}
+		})
+	}
+}
+
+func TestResolveUnwanted(t *testing.T) {
+	pd := &fakePagerDuty{}
+	srv := httptest.NewServer(pd)
+	defer srv.Close()
+	outage := providers.Incident{ID: "inc1", Name: "API down", Severity: providers.StatusMajorOutage}
+
+	m := newTestMirror(t, srv.URL,
+		config.Page{Name: "github", IncidentMirror: config.PageMirror{PagerDutyRoutingKey: "GITHUB"}},
+		config.Page{Name: "stripe"},
+	)
+	m.reconcile([]collector.PageState{pageState("github", outage), pageState("stripe", outage)})
+	pd.take()
+
+	// github is now disabled and stripe gone from the config; both are still
+	// open at the vendor
+	state := m.statePath
+	next := newTestMirror(t, srv.URL,
+		config.Page{Name: "github", IncidentMirror: config.PageMirror{PagerDutyRoutingKey: "GITHUB", Disabled: true}},
+		config.Page{Name: "slack"},
+	)
+	next.statePath = state
+	j, err := journal.Open(config.Journal{Retention: time.Hour, MaxEvents: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	next.Restore(j)
+	next.reconcile([]collector.PageState{pageState("github", outage), pageState("stripe", outage), pageState("slack")})
+	got := pd.take()
+	want := []string{
+		"resolve statuspage-exporter:github:inc1 GITHUB",
+		"resolve statuspage-exporter:stripe:inc1 DEFAULT",
+	}
+	if len(got) == 2 && got[0] > got[1] {
+		got[0], got[1] = got[1], got[0]
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if n := len(next.open["pagerduty"]); n != 0 {
+		t.Errorf("%d incidents still mirrored", n)
+	}
+}
+
+func TestRestore(t *testing.T) {
+	outage := providers.Incident{ID: "inc1", Name: "API down", Severity: providers.StatusMajorOutage}
+	now := time.Now()
+	journaled := []events.Event{
+		{ID: 1, Type: events.IncidentOpened, Time: now, Page: "github", Severity: providers.StatusMajorOutage, Incident: events.NewIncident(outage)},
+		{ID: 2, Type: events.IncidentOpened, Time: now, Page: "github", Severity: providers.StatusDegraded, Incident: &events.Incident{ID: "minor"}},
+		{ID: 3, Type: events.IncidentOpened, Time: now, Page: "github", Severity: providers.StatusMajorOutage, Incident: &events.Incident{ID: "gone"}},
+		{ID: 4, Type: events.IncidentResolved, Time: now, Page: "github", Severity: providers.StatusMajorOutage, Incident: &events.Incident{ID: "gone"}},
+	}
+	tests := []struct {
+		name      string
+		withState bool
+	}{
+		{"from the state file", true},
+		{"from the journal", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pd := &fakePagerDuty{}
+			srv := httptest.NewServer(pd)
+			defer srv.Close()
+			j, err := journal.Open(config.Journal{Retention: time.Hour, MaxEvents: 10})
+			if err != nil {
+				t.Fatal(err)
+			}
+			state := filepath.Join(t.TempDir(), "incident_mirror.json")
+			if tt.withState {
+				m := newTestMirror(t, srv.URL, config.Page{Name: "github"})
+				m.statePath = state
+				m.reconcile([]collector.PageState{pageState("github", outage)})
+				pd.take()
+			} else {
+				for _, ev := range journaled {
+					j.Append(ev)
+				}
+			}
+
+			m := newTestMirror(t, srv.URL, config.Page{Name: "github"})
+			m.statePath = state
+			m.Restore(j)
+			// Resolved by the vendor while the exporter was down
+			m.reconcile([]collector.PageState{pageState("github")})
+			want := []string{"resolve statuspage-exporter:github:inc1 DEFAULT"}
+			if got := pd.take(); !reflect.DeepEqual(got, want) {
+				t.Errorf("got %q, want %q", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package mirror
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/conradoqg/statuspage-exporter/internal/collector"
+	"github.com/conradoqg/statuspage-exporter/internal/outbound"
+	"github.com/conradoqg/statuspage-exporter/internal/providers"
+)
+
+// opsgenie creates and closes alerts through the Alert API, using the dedup
+// key as alias.
+type opsgenie struct {
+	url       string
+	priority  string
+	client    *http.Client
+	userAgent string
+}
+
+type ogAlert struct {
+	Message     string            `json:"message"`
+	Alias       string            `json:"alias"`
+	Description string            `json:"description,omitempty"`
+	Source      string            `json:"source"`
+	Priority    string            `json:"priority"`
+	Tags        []string          `json:"tags,omitempty"`
+	Details     map[string]string `json:"details,omitempty"`
+}
+
+type ogClose struct {
+	Source string `json:"source"`
+	Note   string `json:"note"`
+}
+
+func (o *opsgenie) name() string { return "opsgenie" }
+
+func (o *opsgenie) trigger(key string, r route, ps collector.PageState, inc providers.Incident) error {
+	a := ogAlert{
+		// Opsgenie limits message to 130 characters and alias to 512
+		Message:  truncate(fmt.Sprintf("[%s] %s", ps.Page, inc.Name), 130),
+		Alias:    truncate(key, 512),
+		Source:   "statuspage-exporter",
+		Priority: o.priority,
+		Tags:     []string{"vendor:" + ps.Page, "provider:" + ps.Provider},
+		Details:  details(ps, inc),
+	}
+	for k, v := range ps.Labels {
+		a.Tags = append(a.Tags, k+":"+v)
+	}
+	sort.Strings(a.Tags)
+	var desc []string
+	if inc.Status != "" {
+		desc = append(desc, "Vendor status: "+inc.Status)
+	}
+	if inc.URL != "" {
+		desc = append(desc, inc.URL)
+	}
+	a.Description = strings.Join(desc, "\n")
+	body, err := json.Marshal(a)
+	if err != nil {
+		return fmt.Errorf("encode alert: %w", err)
+	}
+	return outbound.Post(o.client, strings.TrimSuffix(o.url, "/")+"/v2/alerts", o.userAgent, o.headers(r), body)
+}
+
+func (o *opsgenie) resolve(key string, r route) error {
+	body, err := json.Marshal(ogClose{Source: "statuspage-exporter", Note: "Resolved by the vendor"})
+	if err != nil {
+		return fmt.Errorf("encode close: %w", err)
+	}
+	u := strings.TrimSuffix(o.url, "/") + "/v2/alerts/" + url.PathEscape(truncate(key, 512)) + "/close?identifierType=alias"
+	return outbound.Post(o.client, u, o.userAgent, o.headers(r), body)
+}
+
+func (o *opsgenie) headers(r route) map[string]string {
+	return map[string]string{"Authorization": "GenieKey " + r.opsgenieAPIKey}
+}
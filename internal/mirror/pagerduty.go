@@ -0,0 +1,110 @@
+package mirror
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/conradoqg/statuspage-exporter/internal/collector"
+	"github.com/conradoqg/statuspage-exporter/internal/outbound"
+	"github.com/conradoqg/statuspage-exporter/internal/providers"
+)
+
+// pagerDuty sends Events API v2 trigger/resolve events.
+type pagerDuty struct {
+	url       string
+	severity  string
+	client    *http.Client
+	userAgent string
+}
+
+type pdEvent struct {
+	RoutingKey  string     `json:"routing_key"`
+	EventAction string     `json:"event_action"`
+	DedupKey    string     `json:"dedup_key"`
+	Payload     *pdPayload `json:"payload,omitempty"`
+	Links       []pdLink   `json:"links,omitempty"`
+}
+
+type pdPayload struct {
+	Summary       string            `json:"summary"`
+	Source        string            `json:"source"`
+	Severity      string            `json:"severity"`
+	Timestamp     *time.Time        `json:"timestamp,omitempty"`
+	Group         string            `json:"group,omitempty"`
+	Class         string            `json:"class,omitempty"`
+	CustomDetails map[string]string `json:"custom_details,omitempty"`
+}
+
+type pdLink struct {
+	Href string `json:"href"`
+	Text string `json:"text"`
+}
+
+func (p *pagerDuty) name() string { return "pagerduty" }
+
+func (p *pagerDuty) trigger(key string, r route, ps collector.PageState, inc providers.Incident) error {
+	ev := pdEvent{
+		RoutingKey:  r.pagerDutyKey,
+		EventAction: "trigger",
+		DedupKey:    key,
+		Payload: &pdPayload{
+			// PagerDuty truncates summaries at 1024 characters
+			Summary:       truncate(fmt.Sprintf("[%s] %s", ps.Page, inc.Name), 1024),
+			Source:        ps.Page,
+			Severity:      p.severity,
+			Group:         ps.Provider,
+			Class:         "vendor_incident",
+			CustomDetails: details(ps, inc),
+		},
+	}
+	if !inc.StartedAt.IsZero() {
+		ts := inc.StartedAt.UTC()
+		ev.Payload.Timestamp = &ts
+	}
+	if inc.URL != "" {
+		ev.Links = append(ev.Links, pdLink{Href: inc.URL, Text: "Vendor incident"})
+	}
+	if ps.URL != "" {
+		ev.Links = append(ev.Links, pdLink{Href: ps.URL, Text: "Vendor status page"})
+	}
+	return p.send(ev)
+}
+
+func (p *pagerDuty) resolve(key string, r route) error {
+	return p.send(pdEvent{RoutingKey: r.pagerDutyKey, EventAction: "resolve", DedupKey: key})
+}
+
+func (p *pagerDuty) send(ev pdEvent) error {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return fmt.Errorf("encode event: %w", err)
+	}
+	return outbound.Post(p.client, p.url, p.userAgent, nil, body)
+}
+
+// details describes the incident for the target's free-form fields.
+func details(ps collector.PageState, inc providers.Incident) map[string]string {
+	out := map[string]string{
+		"provider": ps.Provider,
+		"page":     ps.Page,
+		"severity": inc.Severity.String(),
+	}
+	for k, v := range ps.Labels {
+		out[k] = v
+	}
+	outbound.SetIf(out, "incident_id", inc.ID)
+	outbound.SetIf(out, "status", inc.Status)
+	outbound.SetIf(out, "impact", inc.Impact)
+	outbound.SetIf(out, "url", inc.URL)
+	return out
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
@@ -0,0 +1,259 @@
+// Package notifier posts change events to webhooks (Slack, Teams or any
+// endpoint accepting a templated body).
+package notifier
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"text/template"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/conradoqg/statuspage-exporter/internal/config"
+	"github.com/conradoqg/statuspage-exporter/internal/events"
+	"github.com/conradoqg/statuspage-exporter/internal/logx"
+	"github.com/conradoqg/statuspage-exporter/internal/outbound"
+	"github.com/conradoqg/statuspage-exporter/internal/providers"
+)
+
+// Delivery outcomes, exported as the outcome label
+const (
+	outcomeSent         = "sent"
+	outcomeFailed       = "failed"
+	outcomeDeduplicated = "deduplicated"
+	outcomeRateLimited  = "rate_limited"
+	outcomeDropped      = "dropped"
+)
+
+// Attempts per message; retried on network errors, 429 and 5xx
+const maxAttempts = 3
+
+type Notifier struct {
+	targets  []*target
+	outcomes *prometheus.CounterVec
+}
+
+type target struct {
+	name         string
+	url          string
+	headers      map[string]string
+	userAgent    string
+	tmpl         *template.Template
+	pages        map[string]struct{}
+	labels       map[string]string
+	types        map[events.Type]struct{}
+	minSeverity  providers.NormalizedStatus
+	dedupWindow  time.Duration
+	maxPerMinute int
+	client       *http.Client
+	outcomes     *prometheus.CounterVec
+	queue        chan events.Event
+
+	// Owned by the delivery goroutine
+	sent   map[string]time.Time
+	recent []time.Time
+}
+
+var eventTypes = map[events.Type]struct{}{
+	events.ComponentStatusChanged: {},
+	events.IncidentOpened:         {},
+	events.IncidentResolved:       {},
+	events.PageFetchFailed:        {},
+	events.PageFetchRecovered:     {},
+	events.MaintenanceScheduled:   {},
+	events.MaintenanceCompleted:   {},
+}
+
+// New builds the configured notifiers. namespace prefixes the self metrics.
+func New(cfg *config.Config, namespace string) (*Notifier, error) {
+	n := &Notifier{
+		outcomes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "notifications_total",
+			Help:      "Webhook notifications by notifier and outcome (sent, failed, deduplicated, rate_limited, dropped)",
+		}, []string{"notifier", "outcome"}),
+	}
+	for _, nc := range cfg.Notifiers {
+		text := nc.Template
+		if text == "" {
+			text = presets[nc.Preset]
+		}
+		tmpl, err := parseTemplate(nc.Name, text)
+		if err != nil {
+			return nil, fmt.Errorf("notifier %s: parse template: %w", nc.Name, err)
+		}
+		t := &target{
+			name:         nc.Name,
+			url:          nc.URL,
+			headers:      nc.Headers,
+			userAgent:    cfg.Common.UserAgent,
+			tmpl:         tmpl,
+			labels:       nc.Labels,
+			dedupWindow:  nc.DedupWindow,
+			maxPerMinute: nc.MaxPerMinute,
+			client:       &http.Client{Timeout: nc.Timeout},
+			outcomes:     n.outcomes,
+			queue:        make(chan events.Event, 100),
+			sent:         make(map[string]time.Time),
+		}
+		if len(nc.Pages) > 0 {
+			t.pages = make(map[string]struct{}, len(nc.Pages))
+			for _, p := range nc.Pages {
+				t.pages[p] = struct{}{}
+			}
+		}
+		if len(nc.Events) > 0 {
+			t.types = make(map[events.Type]struct{}, len(nc.Events))
+			for _, s := range nc.Events {
+				typ := events.Type(s)
+				if _, ok := eventTypes[typ]; !ok {
+					return nil, fmt.Errorf("notifier %s: unknown event type %q", nc.Name, s)
+				}
+				t.types[typ] = struct{}{}
+			}
+		}
+		if nc.MinSeverity != "" {
+			t.minSeverity, _ = providers.ParseStatus(nc.MinSeverity)
+		}
+		for _, o := range []string{outcomeSent, outcomeFailed, outcomeDeduplicated, outcomeRateLimited, outcomeDropped} {
+			n.outcomes.WithLabelValues(t.name, o)
+		}
+		n.targets = append(n.targets, t)
+	}
+	return n, nil
+}
+
+// Collector exposes the delivery counters.
+func (n *Notifier) Collector() prometheus.Collector {
+	return n.outcomes
+}
+
+// Run registers on bus and delivers matching events in the background.
+func (n *Notifier) Run(bus *events.Bus) {
+	if len(n.targets) == 0 {
+		return
+	}
+	for _, t := range n.targets {
+		logx.Infof("notifier %s: started", t.name)
+		go t.deliverLoop()
+	}
+	// Enqueued synchronously so every event reaches the targets' queues;
+	// only a full queue drops, and that is counted
+	bus.Handle(func(ev events.Event) {
+		for _, t := range n.targets {
+			if !t.accepts(ev) {
+				continue
+			}
+			select {
+			case t.queue <- ev:
+			default:
+				t.outcomes.WithLabelValues(t.name, outcomeDropped).Inc()
+				logx.Warnf("notifier %s: queue full, dropping event %d", t.name, ev.ID)
+			}
+		}
+	})
+}
+
+func (t *target) accepts(ev events.Event) bool {
+	if t.pages != nil {
+		if _, ok := t.pages[ev.Page]; !ok {
+			return false
+		}
+	}
+	if t.types != nil {
+		if _, ok := t.types[ev.Type]; !ok {
+			return false
+		}
+	}
+	for k, v := range t.labels {
+		if ev.Labels[k] != v {
+			return false
+		}
+	}
+	// Fetch events carry no vendor severity; use events to filter them out
+	if ev.Type == events.PageFetchFailed || ev.Type == events.PageFetchRecovered {
+		return true
+	}
+	return ev.Severity >= t.minSeverity
+}
+
+func (t *target) deliverLoop() {
+	for ev := range t.queue {
+		now := time.Now()
+		if t.duplicate(ev, now) {
+			t.outcomes.WithLabelValues(t.name, outcomeDeduplicated).Inc()
+			logx.Debugf("notifier %s: suppressing duplicate event %d", t.name, ev.ID)
+			continue
+		}
+		if !t.allow(now) {
+			t.outcomes.WithLabelValues(t.name, outcomeRateLimited).Inc()
+			logx.Warnf("notifier %s: rate limit of %d/min reached, dropping event %d", t.name, t.maxPerMinute, ev.ID)
+			continue
+		}
+		if err := t.send(ev); err != nil {
+			t.outcomes.WithLabelValues(t.name, outcomeFailed).Inc()
+			logx.Warnf("notifier %s: event %d: %v", t.name, ev.ID, err)
+			continue
+		}
+		t.outcomes.WithLabelValues(t.name, outcomeSent).Inc()
+	}
+}
+
+// duplicate reports whether an identical event was sent within the dedup
+// window, and records ev otherwise.
+func (t *target) duplicate(ev events.Event, now time.Time) bool {
+	for k, at := range t.sent {
+		if now.Sub(at) >= t.dedupWindow {
+			delete(t.sent, k)
+		}
+	}
+	key := dedupKey(ev)
+	if _, ok := t.sent[key]; ok {
+		return true
+	}
+	t.sent[key] = now
+	return false
+}
+
+// allow applies the per-minute limit over a sliding window.
+func (t *target) allow(now time.Time) bool {
+	keep := t.recent[:0]
+	for _, at := range t.recent {
+		if now.Sub(at) < time.Minute {
+			keep = append(keep, at)
+		}
+	}
+	t.recent = keep
+	if len(t.recent) >= t.maxPerMinute {
+		return false
+	}
+	t.recent = append(t.recent, now)
+	return true
+}
+
+func (t *target) send(ev events.Event) error {
+	var body bytes.Buffer
+	if err := t.tmpl.Execute(&body, ev); err != nil {
+		return fmt.Errorf("render template: %w", err)
+	}
+	var err error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		if err = outbound.Post(t.client, t.url, t.userAgent, t.headers, body.Bytes()); !outbound.Retryable(err) {
+			return err
+		}
+		if attempt < maxAttempts {
+			time.Sleep(time.Duration(attempt) * time.Second)
+		}
+	}
+	return err
+}
+
+func dedupKey(ev events.Event) string {
+	key := fmt.Sprintf("%s|%s|%s|%s|%s|%s|%s", ev.Type, ev.Provider, ev.Page, ev.Component, ev.Group, ev.Region, ev.To)
+	if ev.Incident != nil {
+		key += "|" + ev.Incident.ID + "|" + ev.Incident.Name
+	}
+	return key
+}
@@ -0,0 +1,189 @@
+package notifier
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/conradoqg/statuspage-exporter/internal/config"
+	"github.com/conradoqg/statuspage-exporter/internal/events"
+	"github.com/conradoqg/statuspage-exporter/internal/providers"
+)
+
+func TestAccepts(t *testing.T) {
+	tgt := &target{
+		pages:       map[string]struct{}{"github": {}, "stripe": {}},
+		labels:      map[string]string{"team": "dev"},
+		types:       map[events.Type]struct{}{events.ComponentStatusChanged: {}, events.PageFetchFailed: {}},
+		minSeverity: providers.StatusPartialOutage,
+	}
+	dev := map[string]string{"team": "dev", "env": "prod"}
+	tests := []struct {
+		name string
+		ev   events.Event
+		want bool
+	}{
+		{"matches", events.Event{Type: events.ComponentStatusChanged, Page: "github", Labels: dev, Severity: providers.StatusMajorOutage}, true},
+		{"other page", events.Event{Type: events.ComponentStatusChanged, Page: "slack", Labels: dev, Severity: providers.StatusMajorOutage}, false},
+		{"other type", events.Event{Type: events.IncidentOpened, Page: "github", Labels: dev, Severity: providers.StatusMajorOutage}, false},
+		{"missing label", events.Event{Type: events.ComponentStatusChanged, Page: "github", Severity: providers.StatusMajorOutage}, false},
+		{"below min_severity", events.Event{Type: events.ComponentStatusChanged, Page: "github", Labels: dev, Severity: providers.StatusDegraded}, false},
+		{"fetch events bypass min_severity", events.Event{Type: events.PageFetchFailed, Page: "stripe", Labels: dev}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tgt.accepts(tt.ev); got != tt.want {
+				t.Errorf("accepts = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDuplicate(t *testing.T) {
+	tgt := &target{dedupWindow: 5 * time.Minute, sent: make(map[string]time.Time)}
+	now := time.Now()
+	down := events.Event{ID: 1, Type: events.ComponentStatusChanged, Page: "github", Component: "API", To: "major_outage"}
+	again := down
+	again.ID = 2
+	up := down
+	up.To = "operational"
+	tests := []struct {
+		name string
+		ev   events.Event
+		at   time.Time
+		want bool
+	}{
+		{"first", down, now, false},
+		{"same change with another ID", again, now.Add(time.Minute), true},
+		{"different change", up, now.Add(time.Minute), false},
+		{"after the window", again, now.Add(5 * time.Minute), false},
+	}
+	for _, tt := range tests {
+		if got := tgt.duplicate(tt.ev, tt.at); got != tt.want {
+			t.Errorf("%s: duplicate = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAllow(t *testing.T) {
+	tgt := &target{maxPerMinute: 2}
+	now := time.Now()
+	steps := []struct {
+		at   time.Duration
+		want bool
+	}{
+		{0, true},
+		{10 * time.Second, true},
+		{20 * time.Second, false},
+		// The first message left the window; rejected ones do not count
+		{time.Minute, true},
+		{65 * time.Second, false},
+		{71 * time.Second, true},
+	}
+	for n, s := range steps {
+		if got := tgt.allow(now.Add(s.at)); got != s.want {
+			t.Errorf("step %d (+%v): allow = %v, want %v", n, s.at, got, s.want)
+		}
+	}
+}
+
+func TestDelivery(t *testing.T) {
+	var mu sync.Mutex
+	attempts := 0
+	bodies := make(chan string, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/bad" {
+			http.Error(w, "bad payload", http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		attempts++
+		first := attempts == 1
+		mu.Unlock()
+		if first {
+			http.Error(w, "try again", http.StatusServiceUnavailable)
+			return
+		}
+		if ua := r.Header.Get("User-Agent"); ua != "test-agent" {
+			t.Errorf("User-Agent %q", ua)
+		}
+		b, _ := io.ReadAll(r.Body)
+		bodies <- string(b)
+	}))
+	defer srv.Close()
+
+	cfg := &config.Config{
+		Common: config.Common{UserAgent: "test-agent"},
+		Notifiers: []config.Notifier{
+			{Name: "slack", URL: srv.URL, Preset: config.PresetSlack, DedupWindow: time.Minute, MaxPerMinute: 10, Timeout: time.Second},
+			{Name: "broken", URL: srv.URL + "/bad", Template: `{{ json .Page }}`, Events: []string{"incident_opened"}, DedupWindow: time.Minute, MaxPerMinute: 10, Timeout: time.Second},
+		},
+	}
+	n, err := New(cfg, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bus := events.NewBus(0)
+	n.Run(bus)
+
+	opened := events.Event{Type: events.IncidentOpened, Provider: "statuspage", Page: "github", Severity: providers.StatusMajorOutage,
+		Incident: &events.Incident{ID: "inc1", Name: "Actions delayed"}}
+	bus.Publish(opened)
+	bus.Publish(opened)
+	bus.Publish(events.Event{Type: events.PageFetchFailed, Provider: "statuspage", Page: "stripe", Error: "timeout"})
+
+	// The first attempt fails and is retried
+	for _, want := range []string{"github incident: Actions delayed (major outage)", "stripe status page cannot be fetched"} {
+		select {
+		case body := <-bodies:
+			var msg struct {
+				Attachments []struct {
+					Title string `json:"title"`
+				} `json:"attachments"`
+			}
+			if err := json.Unmarshal([]byte(body), &msg); err != nil {
+				t.Fatalf("invalid body %s: %v", body, err)
+			}
+			if len(msg.Attachments) != 1 || msg.Attachments[0].Title != want {
+				t.Errorf("body %s, want title %q", body, want)
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatalf("%q not delivered", want)
+		}
+	}
+
+	want := map[string]map[string]float64{
+		"slack":  {outcomeSent: 2, outcomeDeduplicated: 1, outcomeFailed: 0},
+		"broken": {outcomeSent: 0, outcomeDeduplicated: 1, outcomeFailed: 1},
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for name, outcomes := range want {
+		for outcome, v := range outcomes {
+			for {
+				var m dto.Metric
+				if err := n.outcomes.WithLabelValues(name, outcome).Write(&m); err != nil {
+					t.Fatal(err)
+				}
+				got := m.GetCounter().GetValue()
+				if got == v {
+					break
+				}
+				if time.Now().After(deadline) {
+					t.Errorf("%s %s = %v, want %v", name, outcome, got, v)
+					break
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+		}
+	}
+	if len(bodies) != 0 {
+		t.Errorf("unexpected extra delivery: %s", strings.TrimSpace(<-bodies))
+	}
+}
@@ -0,0 +1,156 @@
+package notifier
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/conradoqg/statuspage-exporter/internal/config"
+	"github.com/conradoqg/statuspage-exporter/internal/events"
+	"github.com/conradoqg/statuspage-exporter/internal/providers"
+)
+
+// Built-in bodies; custom templates get the same event and functions.
+var presets = map[string]string{
+	config.PresetJSON: `{{ json . }}`,
+	config.PresetSlack: `{"attachments":[{"fallback":{{ json (summary .) }},"color":"{{ color . }}",` +
+		`"title":{{ json (summary .) }},{{ with link . }}"title_link":{{ json . }},{{ end }}` +
+		`"text":{{ json (details .) }},"footer":{{ json .Provider }},"ts":{{ .Time.Unix }}}]}`,
+	config.PresetTeams: `{"type":"message","attachments":[{"contentType":"application/vnd.microsoft.card.adaptive","content":{` +
+		`"$schema":"http://adaptivecards.io/schemas/adaptive-card.json","type":"AdaptiveCard","version":"1.4","msteams":{"width":"Full"},"body":[` +
+		`{"type":"TextBlock","text":{{ json (summary .) }},"weight":"Bolder","size":"Medium","wrap":true},` +
+		`{"type":"TextBlock","text":{{ json (details .) }},"wrap":true,"isSubtle":true}]` +
+		`{{ with link . }},"actions":[{"type":"Action.OpenUrl","title":"Open status page","url":{{ json . }}}]{{ end }}}}]}`,
+}
+
+var funcs = template.FuncMap{
+	"json":     toJSON,
+	"summary":  summary,
+	"details":  details,
+	"link":     link,
+	"color":    color,
+	"duration": duration,
+	"human":    human,
+}
+
+func parseTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(funcs).Option("missingkey=zero").Parse(text)
+}
+
+func toJSON(v any) (string, error) {
+	b, err := json.Marshal(v)
+	return string(b), err
+}
+
+// human turns a status name such as degraded_performance into prose.
+func human(s string) string {
+	return strings.ReplaceAll(s, "_", " ")
+}
+
+// duration formats seconds for humans, e.g. 1h2m3s.
+func duration(seconds float64) string {
+	return (time.Duration(seconds) * time.Second).String()
+}
+
+// summary is a one-line description of the event.
+func summary(ev events.Event) string {
+	switch ev.Type {
+	case events.ComponentStatusChanged:
+		return fmt.Sprintf("%s / %s: %s → %s", ev.Page, ev.Component, human(ev.From), human(ev.To))
+	case events.IncidentOpened:
+		return fmt.Sprintf("%s incident: %s (%s)", ev.Page, incidentName(ev), human(ev.Severity.String()))
+	case events.IncidentResolved:
+		return fmt.Sprintf("%s incident resolved: %s", ev.Page, incidentName(ev))
+	case events.PageFetchFailed:
+		return fmt.Sprintf("%s status page cannot be fetched", ev.Page)
+	case events.PageFetchRecovered:
+		return fmt.Sprintf("%s status page can be fetched again", ev.Page)
+	case events.MaintenanceScheduled:
+		return fmt.Sprintf("%s maintenance scheduled: %s", ev.Page, incidentName(ev))
+	case events.MaintenanceCompleted:
+		return fmt.Sprintf("%s maintenance completed: %s", ev.Page, incidentName(ev))
+	}
+	return fmt.Sprintf("%s: %s", ev.Page, ev.Type)
+}
+
+// details adds context to summary: durations, incident state, errors.
+func details(ev events.Event) string {
+	var parts []string
+	switch ev.Type {
+	case events.ComponentStatusChanged:
+		if ev.Group != "" {
+			parts = append(parts, "Group: "+ev.Group)
+		}
+		if ev.Region != "" {
+			parts = append(parts, "Region: "+ev.Region)
+		}
+		parts = append(parts, fmt.Sprintf("Was %s for %s", human(ev.From), duration(ev.DurationSeconds)))
+	case events.IncidentOpened:
+		if ev.Incident != nil && ev.Incident.Status != "" {
+			parts = append(parts, "Status: "+ev.Incident.Status)
+		}
+		if ev.Incident != nil && ev.Incident.Impact != "" {
+			parts = append(parts, "Impact: "+ev.Incident.Impact)
+		}
+	case events.IncidentResolved:
+		parts = append(parts, "Open for "+duration(ev.DurationSeconds))
+	case events.PageFetchFailed:
+		parts = append(parts, "Error: "+ev.Error)
+	case events.PageFetchRecovered:
+		parts = append(parts, "Failing for "+duration(ev.DurationSeconds))
+	case events.MaintenanceScheduled, events.MaintenanceCompleted:
+		if ev.Incident != nil && !ev.Incident.StartedAt.IsZero() {
+			parts = append(parts, "From: "+ev.Incident.StartedAt.UTC().Format(time.RFC1123))
+		}
+		if ev.Incident != nil && !ev.Incident.EndsAt.IsZero() {
+			parts = append(parts, "Until: "+ev.Incident.EndsAt.UTC().Format(time.RFC1123))
+		}
+	}
+	parts = append(parts, "Provider: "+ev.Provider)
+	return strings.Join(parts, "\n")
+}
+
+// link points at the incident when known, else at the status page.
+func link(ev events.Event) string {
+	if ev.Incident != nil && ev.Incident.URL != "" {
+		return ev.Incident.URL
+	}
+	return ev.URL
+}
+
+// color is a hex color for the event: green for recoveries, else by severity.
+func color(ev events.Event) string {
+	if recovery(ev) {
+		return "#2eb886"
+	}
+	switch ev.Severity {
+	case providers.StatusMajorOutage:
+		return "#d00000"
+	case providers.StatusPartialOutage:
+		return "#f2711c"
+	case providers.StatusDegraded:
+		return "#f2c744"
+	case providers.StatusUnderMaintenance:
+		return "#3f7fd0"
+	}
+	return "#999999"
+}
+
+func recovery(ev events.Event) bool {
+	switch ev.Type {
+	case events.IncidentResolved, events.PageFetchRecovered, events.MaintenanceCompleted:
+		return true
+	case events.ComponentStatusChanged:
+		return ev.To == providers.StatusOperational.String()
+	}
+	return false
+}
+
+func incidentName(ev events.Event) string {
+	if ev.Incident == nil {
+		return ""
+	}
+	return ev.Incident.Name
+}
@@ -0,0 +1,71 @@
+// Package outbound holds the helpers shared by the integrations that push to
+// external services: Alertmanager, the incident mirror and the notifier.
+package outbound
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// StatusError is returned by Post when the receiver answers with a non-2xx
+// status.
+type StatusError struct {
+	Code   int
+	Status string
+	Body   string
+}
+
+func (e *StatusError) Error() string {
+	if e.Body == "" {
+		return "unexpected status: " + e.Status
+	}
+	return "unexpected status: " + e.Status + ": " + e.Body
+}
+
+var errNewRequest = errors.New("new request")
+
+// Post sends body as JSON to url with the given user agent and extra headers.
+func Post(client *http.Client, url, userAgent string, headers map[string]string, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%w: %v", errNewRequest, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("post: %w", err)
+	}
+	defer resp.Body.Close()
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+	if resp.StatusCode >= 300 {
+		return &StatusError{Code: resp.StatusCode, Status: resp.Status, Body: string(bytes.TrimSpace(msg))}
+	}
+	return nil
+}
+
+// Retryable reports whether a Post error is worth retrying: transport
+// errors, rate limiting and server errors.
+func Retryable(err error) bool {
+	if err == nil || errors.Is(err, errNewRequest) {
+		return false
+	}
+	var se *StatusError
+	if errors.As(err, &se) {
+		return se.Code == http.StatusTooManyRequests || se.Code >= 500
+	}
+	return true
+}
+
+// SetIf sets m[k] to v unless v is empty.
+func SetIf(m map[string]string, k, v string) {
+	if v != "" {
+		m[k] = v
+	}
+}
@@ -34,7 +34,7 @@ func NewAWSRSS(name string, feeds []FeedInput, interval, timeout time.Duration)
 		feeds:    feeds,
 		interval: interval,
 		timeout:  timeout,
-		client:   NewHTTPClient(timeout),
+		client:   NewHTTPClient("aws_rss", timeout),
 	}
 }
 
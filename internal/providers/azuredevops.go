@@ -36,7 +36,7 @@ func NewAzureDevOps(name, baseOrAPI string, interval, timeout time.Duration) *Az
 		apiURL:   u,
 		interval: interval,
 		timeout:  timeout,
-		client:   NewHTTPClient(timeout),
+		client:   NewHTTPClient("azuredevops", timeout),
 	}
 }
 
@@ -45,7 +45,9 @@ func (p *AzureDevOpsProvider) Timeout() time.Duration  { return p.timeout }
 
 // Minimal shape
 type azHealth struct {
-	Services []struct {
+	LastUpdated string `json:"lastUpdated"`
+	Services    []struct {
+		ID        string `json:"id"`
 		Name      string `json:"name"`
 		Geography string `json:"geography"`
 		Status    string `json:"status"`
@@ -67,12 +69,14 @@ func (p *AzureDevOpsProvider) Fetch(ctx context.Context) (Result, error) {
 	if err := json.NewDecoder(res.Body).Decode(&h); err != nil {
 		return Result{Provider: "azuredevops", Page: p.name}, err
 	}
-	out := Result{Provider: "azuredevops", Page: p.name}
+	out := Result{Provider: "azuredevops", Page: p.name, UpdatedAt: parseTime(h.LastUpdated)}
 	for _, s := range h.Services {
 		out.Components = append(out.Components, Component{
-			Name:   s.Name,
-			Region: s.Geography,
-			Status: mapAzureDevOps(s.Status),
+			Name:      s.Name,
+			Region:    s.Geography,
+			Status:    mapAzureDevOps(s.Status),
+			RawStatus: s.Status,
+			ID:        s.ID,
 		})
 	}
 	logx.Debugf("azuredevops parsed components=%d page=%s", len(out.Components), p.name)
@@ -28,7 +28,7 @@ func NewBetterStack(name, pageID, apiToken string, interval, timeout time.Durati
 		apiToken:   apiToken,
 		interval:   interval,
 		timeout:    timeout,
-		httpClient: NewHTTPClient(timeout),
+		httpClient: NewHTTPClient("betterstack", timeout),
 	}
 }
 
@@ -70,8 +70,10 @@ func (p *BetterStackProvider) Fetch(ctx context.Context) (Result, error) {
 	}
 	for _, d := range r.Data {
 		out.Components = append(out.Components, Component{
-			Name:   d.Attr.Name,
-			Status: mapBetterStack(d.Attr.Status),
+			Name:      d.Attr.Name,
+			Status:    mapBetterStack(d.Attr.Status),
+			RawStatus: d.Attr.Status,
+			ID:        d.ID,
 		})
 	}
 	return out, nil
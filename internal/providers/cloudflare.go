@@ -41,7 +41,7 @@ func NewCloudflare(name, rawURL string, interval, timeout time.Duration) *Cloudf
 		endpointMode: endpointMode,
 		interval:     interval,
 		timeout:      timeout,
-		client:       NewHTTPClient(timeout),
+		client:       NewHTTPClient("cloudflare", timeout),
 	}
 }
 
@@ -50,16 +50,21 @@ func (p *CloudflareProvider) Timeout() time.Duration  { return p.timeout }
 
 // Minimal summary shape (same as Statuspage summary.json)
 type cfSummary struct {
+	Page struct {
+		UpdatedAt string `json:"updated_at"`
+	} `json:"page"`
 	Components []struct {
-		ID      string `json:"id"`
-		Name    string `json:"name"`
-		Status  string `json:"status"`
-		Group   bool   `json:"group"`
-		GroupID string `json:"group_id"`
+		ID          string `json:"id"`
+		Name        string `json:"name"`
+		Status      string `json:"status"`
+		Description string `json:"description"`
+		UpdatedAt   string `json:"updated_at"`
+		Group       bool   `json:"group"`
+		GroupID     string `json:"group_id"`
 	} `json:"components"`
-	Incidents             []json.RawMessage `json:"incidents"`
-	UnresolvedIncidents   []json.RawMessage `json:"unresolved_incidents"`
-	ScheduledMaintenances []json.RawMessage `json:"scheduled_maintenances"`
+	Incidents             []cfIncident `json:"incidents"`
+	UnresolvedIncidents   []cfIncident `json:"unresolved_incidents"`
+	ScheduledMaintenances []cfIncident `json:"scheduled_maintenances"`
 }
 
 // incidents shape (when a caller points to incidents.json)
@@ -74,7 +79,43 @@ type cfIncident struct {
 	Name       string                `json:"name"`
 	Status     string                `json:"status"`
 	Impact     string                `json:"impact"`
+	Shortlink  string                `json:"shortlink"`
+	CreatedAt  string                `json:"created_at"`
+	StartedAt  string                `json:"started_at"`
+	UpdatedAt  string                `json:"updated_at"`
 	Components []cfIncidentComponent `json:"components"`
+	// Scheduled maintenances only
+	ScheduledFor   string `json:"scheduled_for"`
+	ScheduledUntil string `json:"scheduled_until"`
+}
+
+// toMaintenance converts a scheduled maintenance; its window is the
+// scheduled one.
+func (i cfIncident) toMaintenance() Incident {
+	m := i.toIncident()
+	m.Severity = StatusUnderMaintenance
+	if t := parseTime(i.ScheduledFor); !t.IsZero() {
+		m.StartedAt = t
+	}
+	m.EndsAt = parseTime(i.ScheduledUntil)
+	return m
+}
+
+func (i cfIncident) toIncident() Incident {
+	started := parseTime(i.StartedAt)
+	if started.IsZero() {
+		started = parseTime(i.CreatedAt)
+	}
+	return Incident{
+		ID:        i.ID,
+		Name:      i.Name,
+		Status:    i.Status,
+		Impact:    i.Impact,
+		Severity:  mapIncidentImpact(i.Impact),
+		URL:       i.Shortlink,
+		StartedAt: started,
+		UpdatedAt: parseTime(i.UpdatedAt),
+	}
 }
 
 type cfIncidents struct {
@@ -115,7 +156,7 @@ func (p *CloudflareProvider) Fetch(ctx context.Context) (Result, error) {
 		return Result{Provider: "cloudflare", Page: p.name}, err
 	}
 
-	out := Result{Provider: "cloudflare", Page: p.name}
+	out := Result{Provider: "cloudflare", Page: p.name, UpdatedAt: parseTime(s.Page.UpdatedAt)}
 
 	// If the response contains components (summary.json style), handle like Statuspage
 	if len(s.Components) > 0 {
@@ -132,17 +173,26 @@ func (p *CloudflareProvider) Fetch(ctx context.Context) (Result, error) {
 				continue
 			}
 			out.Components = append(out.Components, Component{
-				Name:   c.Name,
-				Group:  groups[c.GroupID],
-				Status: mapStatuspage(c.Status),
+				Name:        c.Name,
+				Group:       groups[c.GroupID],
+				Status:      mapStatuspage(c.Status),
+				RawStatus:   c.Status,
+				ID:          c.ID,
+				Description: c.Description,
+				UpdatedAt:   parseTime(c.UpdatedAt),
 			})
 		}
 		// Determine open incidents: prefer explicit unresolved incidents from API if present.
 		open := 0
-		if len(s.UnresolvedIncidents) > 0 {
-			open = len(s.UnresolvedIncidents)
-		} else if len(s.Incidents) > 0 {
-			open = len(s.Incidents)
+		incs := s.UnresolvedIncidents
+		if len(incs) == 0 {
+			incs = s.Incidents
+		}
+		for _, inc := range incs {
+			out.Incidents = append(out.Incidents, inc.toIncident())
+		}
+		if len(incs) > 0 {
+			open = len(incs)
 		} else {
 			for _, comp := range out.Components {
 				if comp.Status != StatusOperational && comp.Status != StatusUnderMaintenance {
@@ -151,6 +201,9 @@ func (p *CloudflareProvider) Fetch(ctx context.Context) (Result, error) {
 			}
 		}
 		out.OpenIncidents = open
+		for _, m := range s.ScheduledMaintenances {
+			out.Maintenances = append(out.Maintenances, m.toMaintenance())
+		}
 		logx.Debugf("cloudflare(parsed summary) components=%d open_incidents=%d page=%s", len(out.Components), open, p.name)
 		return out, nil
 	}
@@ -169,20 +222,25 @@ func (p *CloudflareProvider) Fetch(ctx context.Context) (Result, error) {
 				continue
 			}
 			open++
+			out.Incidents = append(out.Incidents, ic.toIncident())
 			// If incident includes affected components, add them
 			if len(ic.Components) > 0 {
 				for _, ac := range ic.Components {
 					out.Components = append(out.Components, Component{
-						Name:   ac.Name,
-						Status: mapCloudflareIncident(ac.Status),
+						Name:      ac.Name,
+						Status:    mapCloudflareIncident(ac.Status),
+						RawStatus: ac.Status,
+						ID:        ac.ID,
 					})
 				}
 				continue
 			}
-			// Otherwise, add a synthetic component per incident with status derived from impact/status
+			// Otherwise, add a synthetic component per incident with status derived
+			// from impact/status; heuristic, so no RawStatus
 			out.Components = append(out.Components, Component{
 				Name:   ic.Name,
 				Status: mapCloudflareIncident(ic.Status + " " + ic.Impact),
+				ID:     ic.ID,
 			})
 		}
 		out.OpenIncidents = open
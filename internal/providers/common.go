@@ -3,6 +3,7 @@ package providers
 import (
 	"context"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -35,12 +36,62 @@ func (s NormalizedStatus) String() string {
 	}
 }
 
+// MarshalText encodes the status by name (e.g. in JSON).
+func (s NormalizedStatus) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText decodes a status name; unrecognized names decode as unknown.
+func (s *NormalizedStatus) UnmarshalText(b []byte) error {
+	*s, _ = ParseStatus(string(b))
+	return nil
+}
+
+// ParseStatus is the inverse of NormalizedStatus.String.
+func ParseStatus(s string) (NormalizedStatus, bool) {
+	for st := StatusUnknown; st <= StatusMajorOutage; st++ {
+		if st.String() == s {
+			return st, true
+		}
+	}
+	return StatusUnknown, false
+}
+
 // Component describes a unit we expose as a metric.
 type Component struct {
 	Name   string
 	Group  string
 	Region string
 	Status NormalizedStatus
+	// RawStatus is the vendor's original status string, empty for heuristic feeds
+	RawStatus string
+	// Optional vendor metadata (stable component id, description, last update)
+	ID          string
+	Description string
+	UpdatedAt   time.Time
+}
+
+// Unmapped reports whether the vendor sent a status value we could not normalize.
+func (c Component) Unmapped() bool {
+	return c.Status == StatusUnknown && c.RawStatus != ""
+}
+
+// Incident is an open vendor incident or scheduled maintenance, when the
+// provider exposes them.
+type Incident struct {
+	ID   string
+	Name string
+	// Vendor lifecycle status (e.g. investigating, identified, monitoring)
+	Status string
+	// Vendor impact wording (e.g. none, minor, major, critical)
+	Impact string
+	// Impact normalized to a component status
+	Severity  NormalizedStatus
+	URL       string
+	StartedAt time.Time
+	UpdatedAt time.Time
+	// Scheduled end, for maintenances
+	EndsAt time.Time
 }
 
 type Result struct {
@@ -50,6 +101,12 @@ type Result struct {
 	Components []Component
 	// OpenIncidents is optional
 	OpenIncidents int
+	// Incidents lists open incidents when the provider exposes details
+	Incidents []Incident
+	// Maintenances lists upcoming and in-progress scheduled maintenances
+	Maintenances []Incident
+	// UpdatedAt is the vendor-reported page update time, zero when not available
+	UpdatedAt time.Time
 }
 
 // Provider scrapes a single page/config and returns a normalized Result.
@@ -59,7 +116,42 @@ type Provider interface {
 	Timeout() time.Duration
 }
 
-// HTTP wrapper with UA and timeout
-func NewHTTPClient(timeout time.Duration) *http.Client {
-	return &http.Client{Timeout: timeout}
+// mapIncidentImpact normalizes Statuspage-style incident impact values.
+func mapIncidentImpact(s string) NormalizedStatus {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "none":
+		return StatusOperational
+	case "maintenance":
+		return StatusUnderMaintenance
+	case "minor":
+		return StatusDegraded
+	case "major":
+		return StatusPartialOutage
+	case "critical":
+		return StatusMajorOutage
+	default:
+		return StatusUnknown
+	}
+}
+
+// parseTime parses vendor timestamps leniently; unparseable values yield zero time.
+func parseTime(s string) time.Time {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}
+	}
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999", "2006-01-02 15:04:05"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// HTTP wrapper with UA and timeout; requests are instrumented per provider and host
+func NewHTTPClient(provider string, timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: &instrumentedTransport{provider: provider, next: http.DefaultTransport},
+	}
 }
@@ -31,7 +31,7 @@ func NewGCP(name, url string, interval, timeout time.Duration) *GCPProvider {
 		url:      url,
 		interval: interval,
 		timeout:  timeout,
-		client:   NewHTTPClient(timeout),
+		client:   NewHTTPClient("gcp", timeout),
 	}
 }
 
@@ -70,8 +70,10 @@ func (p *GCPProvider) Fetch(ctx context.Context) (Result, error) {
 		open++
 		// Severity mapping from most_recent_update.severity
 		var stCode NormalizedStatus = StatusDegraded
+		var rawSev string
 		if mru, ok := inc["most_recent_update"].(map[string]any); ok {
 			if sev, ok := mru["severity"].(string); ok {
+				rawSev = sev
 				switch strings.ToLower(sev) {
 				case "low":
 					stCode = StatusDegraded
@@ -109,11 +111,28 @@ func (p *GCPProvider) Fetch(ctx context.Context) (Result, error) {
 			}
 		}
 
+		desc, _ := inc["external_desc"].(string)
+		var updated time.Time
+		if mod, ok := inc["modified"].(string); ok {
+			updated = parseTime(mod)
+		}
+		incident := Incident{Name: desc, Status: "open", Impact: rawSev, Severity: stCode, UpdatedAt: updated}
+		incident.ID, _ = inc["id"].(string)
+		if begin, ok := inc["begin"].(string); ok {
+			incident.StartedAt = parseTime(begin)
+		}
+		if uri, ok := inc["uri"].(string); ok && uri != "" {
+			incident.URL = "https://status.cloud.google.com/" + strings.TrimLeft(uri, "/")
+		}
+		out.Incidents = append(out.Incidents, incident)
 		// Emit one component per affected product
 		for _, prod := range prods {
 			out.Components = append(out.Components, Component{
-				Name:   prod,
-				Status: stCode,
+				Name:        prod,
+				Status:      stCode,
+				RawStatus:   rawSev,
+				Description: desc,
+				UpdatedAt:   updated,
 			})
 		}
 	}
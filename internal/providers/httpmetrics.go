@@ -0,0 +1,72 @@
+package providers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Outbound HTTP instrumentation shared by every provider client.
+var httpMetrics = newHTTPMetrics("statuspage_exporter")
+
+type httpMetricSet struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	inFlight *prometheus.GaugeVec
+}
+
+func newHTTPMetrics(namespace string) *httpMetricSet {
+	return &httpMetricSet{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Outbound HTTP requests by provider, host, method and status code (code=error on transport failure)",
+		}, []string{"provider", "host", "method", "code"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Outbound HTTP request latency by provider and host",
+			Buckets:   []float64{.05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+		}, []string{"provider", "host"}),
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "http_requests_in_flight",
+			Help:      "Outbound HTTP requests currently in flight by provider and host",
+		}, []string{"provider", "host"}),
+	}
+}
+
+// SetMetricsNamespace renames the outbound HTTP metrics; call it before
+// creating providers and registering HTTPCollectors.
+func SetMetricsNamespace(namespace string) {
+	httpMetrics = newHTTPMetrics(namespace)
+}
+
+// HTTPCollectors returns the outbound HTTP metrics so callers can register them.
+func HTTPCollectors() []prometheus.Collector {
+	return []prometheus.Collector{httpMetrics.requests, httpMetrics.duration, httpMetrics.inFlight}
+}
+
+type instrumentedTransport struct {
+	provider string
+	next     http.RoundTripper
+}
+
+func (t *instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := req.URL.Host
+	inFlight := httpMetrics.inFlight.WithLabelValues(t.provider, host)
+	inFlight.Inc()
+	defer inFlight.Dec()
+
+	start := time.Now()
+	res, err := t.next.RoundTrip(req)
+	httpMetrics.duration.WithLabelValues(t.provider, host).Observe(time.Since(start).Seconds())
+	code := "error"
+	if err == nil {
+		code = strconv.Itoa(res.StatusCode)
+	}
+	httpMetrics.requests.WithLabelValues(t.provider, host, req.Method, code).Inc()
+	return res, err
+}
@@ -29,7 +29,7 @@ func NewInstatus(name, baseURL string, interval, timeout time.Duration) *Instatu
 		baseURL:  strings.TrimRight(baseURL, "/"),
 		interval: interval,
 		timeout:  timeout,
-		client:   NewHTTPClient(timeout),
+		client:   NewHTTPClient("instatus", timeout),
 	}
 }
 
@@ -37,9 +37,12 @@ func (p *InstatusProvider) Interval() time.Duration { return p.interval }
 func (p *InstatusProvider) Timeout() time.Duration  { return p.timeout }
 
 type instatusComponent struct {
-	Name   string `json:"name"`
-	Status string `json:"status"`
-	Group  string `json:"group_name"`
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Status      string `json:"status"`
+	Group       string `json:"group_name"`
+	UpdatedAt   string `json:"updatedAt"`
 }
 
 type instatusV2 struct {
@@ -69,9 +72,13 @@ func (p *InstatusProvider) Fetch(ctx context.Context) (Result, error) {
 	out := Result{Provider: "instatus", Page: p.name}
 	for _, c := range v.Components {
 		out.Components = append(out.Components, Component{
-			Name:   c.Name,
-			Group:  c.Group,
-			Status: mapInstatus(c.Status),
+			Name:        c.Name,
+			Group:       c.Group,
+			Status:      mapInstatus(c.Status),
+			RawStatus:   c.Status,
+			ID:          c.ID,
+			Description: c.Description,
+			UpdatedAt:   parseTime(c.UpdatedAt),
 		})
 	}
 	logx.Debugf("instatus parsed components=%d page=%s", len(out.Components), p.name)
@@ -100,10 +107,17 @@ func (p *InstatusProvider) fetchLegacy(ctx context.Context) (Result, error) {
 			name, _ := m["name"].(string)
 			status, _ := m["status"].(string)
 			group, _ := m["group_name"].(string)
+			id, _ := m["id"].(string)
+			desc, _ := m["description"].(string)
+			updated, _ := m["updatedAt"].(string)
 			out.Components = append(out.Components, Component{
-				Name:   name,
-				Group:  group,
-				Status: mapInstatus(status),
+				Name:        name,
+				Group:       group,
+				Status:      mapInstatus(status),
+				RawStatus:   status,
+				ID:          id,
+				Description: desc,
+				UpdatedAt:   parseTime(updated),
 			})
 		}
 	}
@@ -26,7 +26,7 @@ func NewStatusIO(name, rssURL string, interval, timeout time.Duration) *StatusIO
 		rssURL:   rssURL,
 		interval: interval,
 		timeout:  timeout,
-		client:   NewHTTPClient(timeout),
+		client:   NewHTTPClient("statusio_rss", timeout),
 	}
 }
 
@@ -28,7 +28,7 @@ func NewStatuspage(name, baseURL string, interval, timeout time.Duration) *Statu
 		baseURL:  strings.TrimRight(baseURL, "/"),
 		interval: interval,
 		timeout:  timeout,
-		client:   NewHTTPClient(timeout),
+		client:   NewHTTPClient("statuspage", timeout),
 	}
 }
 
@@ -36,16 +36,64 @@ func (p *StatuspageProvider) Interval() time.Duration { return p.interval }
 func (p *StatuspageProvider) Timeout() time.Duration  { return p.timeout }
 
 type spSummary struct {
+	Page struct {
+		UpdatedAt string `json:"updated_at"`
+	} `json:"page"`
 	Components []struct {
-		ID      string `json:"id"`
-		Name    string `json:"name"`
-		Status  string `json:"status"`
-		Group   bool   `json:"group"`
-		GroupID string `json:"group_id"`
+		ID          string `json:"id"`
+		Name        string `json:"name"`
+		Status      string `json:"status"`
+		Description string `json:"description"`
+		UpdatedAt   string `json:"updated_at"`
+		Group       bool   `json:"group"`
+		GroupID     string `json:"group_id"`
 	} `json:"components"`
-	Incidents             []json.RawMessage `json:"incidents"`
-	UnresolvedIncidents   []json.RawMessage `json:"unresolved_incidents"`
-	ScheduledMaintenances []json.RawMessage `json:"scheduled_maintenances"`
+	Incidents             []spIncident `json:"incidents"`
+	UnresolvedIncidents   []spIncident `json:"unresolved_incidents"`
+	ScheduledMaintenances []spIncident `json:"scheduled_maintenances"`
+}
+
+type spIncident struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	Impact    string `json:"impact"`
+	Shortlink string `json:"shortlink"`
+	CreatedAt string `json:"created_at"`
+	StartedAt string `json:"started_at"`
+	UpdatedAt string `json:"updated_at"`
+	// Scheduled maintenances only
+	ScheduledFor   string `json:"scheduled_for"`
+	ScheduledUntil string `json:"scheduled_until"`
+}
+
+// toMaintenance converts a scheduled maintenance; its window is the
+// scheduled one.
+func (i spIncident) toMaintenance() Incident {
+	m := i.toIncident()
+	m.Severity = StatusUnderMaintenance
+	if t := parseTime(i.ScheduledFor); !t.IsZero() {
+		m.StartedAt = t
+	}
+	m.EndsAt = parseTime(i.ScheduledUntil)
+	return m
+}
+
+func (i spIncident) toIncident() Incident {
+	started := parseTime(i.StartedAt)
+	if started.IsZero() {
+		started = parseTime(i.CreatedAt)
+	}
+	return Incident{
+		ID:        i.ID,
+		Name:      i.Name,
+		Status:    i.Status,
+		Impact:    i.Impact,
+		Severity:  mapIncidentImpact(i.Impact),
+		URL:       i.Shortlink,
+		StartedAt: started,
+		UpdatedAt: parseTime(i.UpdatedAt),
+	}
 }
 
 func (p *StatuspageProvider) Fetch(ctx context.Context) (Result, error) {
@@ -63,7 +111,7 @@ func (p *StatuspageProvider) Fetch(ctx context.Context) (Result, error) {
 	if err := json.NewDecoder(res.Body).Decode(&s); err != nil {
 		return Result{Provider: "statuspage", Page: p.name}, err
 	}
-	out := Result{Provider: "statuspage", Page: p.name}
+	out := Result{Provider: "statuspage", Page: p.name, UpdatedAt: parseTime(s.Page.UpdatedAt)}
 	// Map group id -> group name
 	groups := make(map[string]string)
 	for _, c := range s.Components {
@@ -77,18 +125,27 @@ func (p *StatuspageProvider) Fetch(ctx context.Context) (Result, error) {
 			continue
 		}
 		out.Components = append(out.Components, Component{
-			Name:   c.Name,
-			Group:  groups[c.GroupID],
-			Status: mapStatuspage(c.Status),
+			Name:        c.Name,
+			Group:       groups[c.GroupID],
+			Status:      mapStatuspage(c.Status),
+			RawStatus:   c.Status,
+			ID:          c.ID,
+			Description: c.Description,
+			UpdatedAt:   parseTime(c.UpdatedAt),
 		})
 	}
 	// Determine open incidents: prefer explicit unresolved incidents from API if present.
 	open := 0
-	if len(s.UnresolvedIncidents) > 0 {
-		open = len(s.UnresolvedIncidents)
-	} else if len(s.Incidents) > 0 {
+	incs := s.UnresolvedIncidents
+	if len(incs) == 0 {
 		// Many pages return only `incidents` in summary.json as unresolved
-		open = len(s.Incidents)
+		incs = s.Incidents
+	}
+	for _, inc := range incs {
+		out.Incidents = append(out.Incidents, inc.toIncident())
+	}
+	if len(incs) > 0 {
+		open = len(incs)
 	} else {
 		// Fallback: count non-operational components (excluding maintenance)
 		for _, comp := range out.Components {
@@ -98,6 +155,9 @@ func (p *StatuspageProvider) Fetch(ctx context.Context) (Result, error) {
 		}
 	}
 	out.OpenIncidents = open
+	for _, m := range s.ScheduledMaintenances {
+		out.Maintenances = append(out.Maintenances, m.toMaintenance())
+	}
 	logx.Debugf("statuspage parsed components=%d open_incidents=%d page=%s", len(out.Components), open, p.name)
 	return out, nil
 }
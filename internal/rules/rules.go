@@ -0,0 +1,281 @@
+// Package rules renders Prometheus alerting and recording rules for the
+// configured pages, as a rules file or a PrometheusRule resource.
+package rules
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"gopkg.in/yaml.v3"
+
+	"github.com/conradoqg/statuspage-exporter/internal/config"
+)
+
+// Output formats
+const (
+	FormatRules = "rules"
+	FormatCRD   = "crd"
+)
+
+// Alert names
+const (
+	alertComponentDown = "VendorComponentDown"
+	alertScrapeFailing = "VendorStatusPageScrapeFailing"
+	alertAbsent        = "VendorStatusPageAbsent"
+	alertStale         = "VendorStatusPageStale"
+	alertSLA           = "VendorSLABreached"
+)
+
+// File is a Prometheus rules file.
+type File struct {
+	Groups []Group `yaml:"groups"`
+}
+
+type Group struct {
+	Name  string `yaml:"name"`
+	Rules []Rule `yaml:"rules"`
+}
+
+type Rule struct {
+	Record      string            `yaml:"record,omitempty"`
+	Alert       string            `yaml:"alert,omitempty"`
+	Expr        string            `yaml:"expr"`
+	For         string            `yaml:"for,omitempty"`
+	Labels      map[string]string `yaml:"labels,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+}
+
+type prometheusRule struct {
+	APIVersion string       `yaml:"apiVersion"`
+	Kind       string       `yaml:"kind"`
+	Metadata   ruleMetadata `yaml:"metadata"`
+	Spec       File         `yaml:"spec"`
+}
+
+type ruleMetadata struct {
+	Name      string            `yaml:"name"`
+	Namespace string            `yaml:"namespace,omitempty"`
+	Labels    map[string]string `yaml:"labels,omitempty"`
+}
+
+// Labels identifying a component series besides the extra page labels
+const componentLabels = "provider, page, component, group, region"
+
+// Generate builds one rule group per page that is not disabled.
+func Generate(cfg *config.Config) File {
+	f := File{Groups: make([]Group, 0, len(cfg.Pages))}
+	for _, p := range cfg.Pages {
+		if p.Rules.Disabled {
+			continue
+		}
+		f.Groups = append(f.Groups, pageGroup(cfg, p))
+	}
+	return f
+}
+
+// Render encodes the rules of cfg in the given format.
+func Render(cfg *config.Config, format string) ([]byte, error) {
+	var doc any
+	switch format {
+	case FormatRules:
+		doc = Generate(cfg)
+	case FormatCRD:
+		crd := cfg.Rules.CRD
+		doc = prometheusRule{
+			APIVersion: "monitoring.coreos.com/v1",
+			Kind:       "PrometheusRule",
+			Metadata:   ruleMetadata{Name: crd.Name, Namespace: crd.Namespace, Labels: crd.Labels},
+			Spec:       Generate(cfg),
+		}
+	default:
+		return nil, fmt.Errorf("unknown format %q (want rules|crd)", format)
+	}
+	var buf bytes.Buffer
+	buf.WriteString("# Generated by statuspage-exporter rules from the exporter config; do not edit.\n")
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return nil, fmt.Errorf("encode rules: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("encode rules: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func pageGroup(cfg *config.Config, p config.Page) Group {
+	r := cfg.Rules
+	prefix := cfg.Common.MetricPrefix
+	sel := `page="` + escape(p.Name) + `"`
+	pageLabels := labelsOf(cfg, p)
+	severity := pageSeverity(cfg, p, pageLabels)
+	alertLabels := func(sev string) map[string]string {
+		l := make(map[string]string, len(r.Labels)+len(pageLabels)+2)
+		for k, v := range r.Labels {
+			l[k] = v
+		}
+		// Also set on the series, but absent() drops them
+		for k, v := range pageLabels {
+			l[k] = v
+		}
+		l["page"] = p.Name
+		l["severity"] = sev
+		return l
+	}
+	link := p.UserFriendlyURL
+	if link == "" {
+		link = p.URL
+	}
+	annotations := func(summary, description string) map[string]string {
+		a := map[string]string{"summary": summary, "description": description}
+		if link != "" {
+			a["status_page"] = link
+		}
+		return a
+	}
+
+	up := prefix + "_component_up"
+	pageUp := "page:" + up + ":min"
+	g := Group{Name: r.GroupPrefix + "." + p.Name}
+
+	// SLA recording rules: share of time each component, and the whole page,
+	// was operational (maintenance counts as down)
+	g.Rules = append(g.Rules, Rule{
+		Record: pageUp,
+		Expr:   fmt.Sprintf("min without (component, group, region, canonical_region, continent) (%s{%s})", up, sel),
+	})
+	var longest time.Duration
+	for _, w := range r.SLAWindows {
+		d := promDuration(w)
+		g.Rules = append(g.Rules,
+			Rule{
+				Record: "component:" + up + ":avg_over_time_" + d,
+				Expr:   fmt.Sprintf("avg_over_time(%s{%s}[%s])", up, sel, d),
+			},
+			Rule{
+				Record: "page:" + up + ":avg_over_time_" + d,
+				Expr:   fmt.Sprintf("avg_over_time(%s{%s}[%s])", pageUp, sel, d),
+			},
+		)
+		if w > longest {
+			longest = w
+		}
+	}
+
+	// Maintenance is reported as not up; only alert on unplanned outages
+	maintenance := fmt.Sprintf(`%s_component_status_code{%s, status="under_maintenance"}`, prefix, sel)
+	if cfg.Common.StatusMetric == config.StatusMetricStateSet {
+		maintenance = fmt.Sprintf(`%s_component_state{%s, state="under_maintenance"} == 1`, prefix, sel)
+	}
+	forDown := r.For
+	if p.Rules.For != nil {
+		forDown = *p.Rules.For
+	}
+	g.Rules = append(g.Rules, Rule{
+		Alert:  alertComponentDown,
+		Expr:   fmt.Sprintf("%s{%s} == 0 unless on (%s) %s", up, sel, componentLabels, maintenance),
+		For:    promDuration(forDown),
+		Labels: alertLabels(severity),
+		Annotations: annotations(
+			"{{ $labels.page }} / {{ $labels.component }} is not operational",
+			"The vendor reports {{ $labels.component }} on the {{ $labels.page }} status page as not operational for more than "+promDuration(forDown)+".",
+		),
+	})
+
+	g.Rules = append(g.Rules,
+		Rule{
+			Alert:  alertScrapeFailing,
+			Expr:   fmt.Sprintf("%s_scrape_success{%s} == 0", prefix, sel),
+			For:    promDuration(r.ScrapeFailureFor),
+			Labels: alertLabels(r.DefaultSeverity),
+			Annotations: annotations(
+				"The {{ $labels.page }} status page cannot be fetched",
+				"statuspage-exporter has failed to fetch the {{ $labels.page }} status page for more than "+promDuration(r.ScrapeFailureFor)+"; its component metrics are not updated.",
+			),
+		},
+		Rule{
+			Alert:  alertAbsent,
+			Expr:   fmt.Sprintf("absent(%s_scrape_success{%s})", prefix, sel),
+			For:    promDuration(r.ScrapeFailureFor),
+			Labels: alertLabels(r.DefaultSeverity),
+			Annotations: annotations(
+				"No data for the "+p.Name+" status page",
+				"Prometheus has no "+prefix+"_scrape_success series for page "+p.Name+"; the exporter may be down or no longer configured for it.",
+			),
+		},
+	)
+
+	stale := r.StaleAfter
+	if p.Rules.StaleAfter != nil {
+		stale = *p.Rules.StaleAfter
+	}
+	if stale > 0 {
+		g.Rules = append(g.Rules, Rule{
+			Alert:  alertStale,
+			Expr:   fmt.Sprintf("time() - %s_page_last_vendor_update_timestamp_seconds{%s} > %s", prefix, sel, strconv.FormatFloat(stale.Seconds(), 'f', -1, 64)),
+			Labels: alertLabels(r.DefaultSeverity),
+			Annotations: annotations(
+				"The {{ $labels.page }} status page has not been updated for "+promDuration(stale),
+				"The vendor last updated the {{ $labels.page }} status page {{ $value | humanizeDuration }} ago; it may be abandoned or moved.",
+			),
+		})
+	}
+
+	target := r.SLATarget
+	if p.Rules.SLATarget != nil {
+		target = *p.Rules.SLATarget
+	}
+	if target > 0 && longest > 0 {
+		window := promDuration(longest)
+		g.Rules = append(g.Rules, Rule{
+			Alert:  alertSLA,
+			Expr:   fmt.Sprintf("page:%s:avg_over_time_%s{%s} < %s", up, window, sel, strconv.FormatFloat(target, 'f', -1, 64)),
+			Labels: alertLabels(severity),
+			Annotations: annotations(
+				"{{ $labels.page }} availability is below "+strconv.FormatFloat(target*100, 'f', -1, 64)+"% over "+window,
+				"All components of the {{ $labels.page }} status page were operational {{ $value | humanizePercentage }} of the last "+window+".",
+			),
+		})
+	}
+	return g
+}
+
+// labelsOf merges the common and page labels, as the exporter does for series.
+func labelsOf(cfg *config.Config, p config.Page) map[string]string {
+	out := make(map[string]string, len(cfg.Common.Labels)+len(p.Labels))
+	for k, v := range cfg.Common.Labels {
+		out[k] = v
+	}
+	for k, v := range p.Labels {
+		out[k] = v
+	}
+	return out
+}
+
+// pageSeverity is the page's explicit severity, else the one mapped from its
+// criticality label, else the default.
+func pageSeverity(cfg *config.Config, p config.Page, labels map[string]string) string {
+	if p.Rules.Severity != "" {
+		return p.Rules.Severity
+	}
+	if sev, ok := cfg.Rules.Severities[strings.ToLower(labels[cfg.Rules.CriticalityLabel])]; ok {
+		return sev
+	}
+	return cfg.Rules.DefaultSeverity
+}
+
+// promDuration formats d the way Prometheus does (e.g. 5m, 1d, 1w).
+func promDuration(d time.Duration) string {
+	return model.Duration(d).String()
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escape quotes s for use inside a PromQL double-quoted label value.
+func escape(s string) string {
+	return labelValueEscaper.Replace(s)
+}
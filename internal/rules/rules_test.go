@@ -0,0 +1,224 @@
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/conradoqg/statuspage-exporter/internal/config"
+)
+
+func loadConfig(t *testing.T, text string) *config.Config {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	return cfg
+}
+
+const testConfig = `
+common:
+  labels: {env: prod}
+rules:
+  stale_after: 12h
+  sla_windows: [24h, 720h]
+  sla_target: 0.995
+  labels: {source: vendors}
+pages:
+  - name: github
+    type: statuspage
+    url: https://www.githubstatus.com
+    labels: {criticality: High}
+  - name: stripe
+    type: statuspage
+    url: https://status.stripe.com
+    user_friendly_url: https://stripe.com/status
+    labels: {criticality: low}
+    rules: {for: 15m, stale_after: 0s, sla_target: 0, severity: critical}
+  - name: slack
+    type: statuspage
+    url: https://status.slack.com
+    rules: {disabled: true}
+`
+
+// find returns the rule recording or alerting as name.
+func find(g Group, name string) (Rule, bool) {
+	for _, r := range g.Rules {
+		if r.Record == name || r.Alert == name {
+			return r, true
+		}
+	}
+	return Rule{}, false
+}
+
+func TestGenerate(t *testing.T) {
+	f := Generate(loadConfig(t, testConfig))
+	if len(f.Groups) != 2 || f.Groups[0].Name != "statuspage-exporter.github" || f.Groups[1].Name != "statuspage-exporter.stripe" {
+		t.Fatalf("unexpected groups %+v", f.Groups)
+	}
+	github, stripe := f.Groups[0], f.Groups[1]
+
+	var names []string
+	for _, r := range github.Rules {
+		names = append(names, r.Record+r.Alert)
+	}
+	wantNames := []string{
+		"page:statuspage_component_up:min",
+		"component:statuspage_component_up:avg_over_time_1d",
+		"page:statuspage_component_up:avg_over_time_1d",
+		"component:statuspage_component_up:avg_over_time_30d",
+		"page:statuspage_component_up:avg_over_time_30d",
+		"VendorComponentDown",
+		"VendorStatusPageScrapeFailing",
+		"VendorStatusPageAbsent",
+		"VendorStatusPageStale",
+		"VendorSLABreached",
+	}
+	if !reflect.DeepEqual(names, wantNames) {
+		t.Errorf("github rules %q, want %q", names, wantNames)
+	}
+
+	down, _ := find(github, alertComponentDown)
+	wantDown := Rule{
+		Alert: alertComponentDown,
+		Expr: `statuspage_component_up{page="github"} == 0 unless on (provider, page, component, group, region) ` +
+			`statuspage_component_status_code{page="github", status="under_maintenance"}`,
+		For:    "5m",
+		Labels: map[string]string{"source": "vendors", "env": "prod", "criticality": "High", "page": "github", "severity": "critical"},
+		Annotations: map[string]string{
+			"summary":     "{{ $labels.page }} / {{ $labels.component }} is not operational",
+			"description": "The vendor reports {{ $labels.component }} on the {{ $labels.page }} status page as not operational for more than 5m.",
+			"status_page": "https://www.githubstatus.com",
+		},
+	}
+	if !reflect.DeepEqual(down, wantDown) {
+		t.Errorf("component alert\n got %+v\nwant %+v", down, wantDown)
+	}
+
+	tests := []struct {
+		name   string
+		group  Group
+		rule   string
+		expr   string
+		labels map[string]string
+	}{
+		{"scrape alerts use the default severity", github, alertScrapeFailing, `statuspage_scrape_success{page="github"} == 0`,
+			map[string]string{"source": "vendors", "env": "prod", "criticality": "High", "page": "github", "severity": "warning"}},
+		{"stale", github, alertStale, `time() - statuspage_page_last_vendor_update_timestamp_seconds{page="github"} > 43200`, nil},
+		{"SLA over the longest window", github, alertSLA, `page:statuspage_component_up:avg_over_time_30d{page="github"} < 0.995`, nil},
+		{"severity override beats criticality", stripe, alertComponentDown, "",
+			map[string]string{"source": "vendors", "env": "prod", "criticality": "low", "page": "stripe", "severity": "critical"}},
+	}
+	for _, tt := range tests {
+		r, ok := find(tt.group, tt.rule)
+		if !ok {
+			t.Errorf("%s: %s missing", tt.name, tt.rule)
+			continue
+		}
+		if tt.expr != "" && r.Expr != tt.expr {
+			t.Errorf("%s: expr %q, want %q", tt.name, r.Expr, tt.expr)
+		}
+		if tt.labels != nil && !reflect.DeepEqual(r.Labels, tt.labels) {
+			t.Errorf("%s: labels %v, want %v", tt.name, r.Labels, tt.labels)
+		}
+	}
+
+	// Page overrides
+	if r, _ := find(stripe, alertComponentDown); r.For != "15m" || r.Annotations["status_page"] != "https://stripe.com/status" {
+		t.Errorf("stripe component alert %+v", r)
+	}
+	for _, name := range []string{alertStale, alertSLA} {
+		if _, ok := find(stripe, name); ok {
+			t.Errorf("stripe has %s although disabled", name)
+		}
+	}
+}
+
+func TestSeverityMapping(t *testing.T) {
+	cfg := loadConfig(t, `
+rules:
+  severities: {p1: page}
+pages:
+  - {name: a, type: statuspage, url: https://a.example.com, labels: {criticality: P1}}
+  - {name: b, type: statuspage, url: https://b.example.com, labels: {criticality: p2}}
+  - {name: c, type: statuspage, url: https://c.example.com}
+`)
+	want := map[string]string{"a": "page", "b": "warning", "c": "warning"}
+	for _, g := range Generate(cfg).Groups {
+		r, _ := find(g, alertComponentDown)
+		page := r.Labels["page"]
+		if r.Labels["severity"] != want[page] {
+			t.Errorf("%s: severity %q, want %q", page, r.Labels["severity"], want[page])
+		}
+	}
+}
+
+func TestStateSetMaintenance(t *testing.T) {
+	cfg := loadConfig(t, `
+common:
+  metric_prefix: vendor
+  status_metric: stateset
+pages:
+  - {name: github, type: statuspage, url: https://www.githubstatus.com}
+`)
+	r, _ := find(Generate(cfg).Groups[0], alertComponentDown)
+	want := `vendor_component_up{page="github"} == 0 unless on (provider, page, component, group, region) ` +
+		`vendor_component_state{page="github", state="under_maintenance"} == 1`
+	if r.Expr != want {
+		t.Errorf("expr %q, want %q", r.Expr, want)
+	}
+}
+
+func TestRender(t *testing.T) {
+	cfg := loadConfig(t, testConfig+`
+  - name: extra
+    type: statuspage
+    url: https://status.example.com
+`)
+	cfg.Rules.CRD.Namespace = "monitoring"
+	cfg.Rules.CRD.Labels = map[string]string{"release": "kube-prometheus"}
+
+	b, err := Render(cfg, FormatRules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var f File
+	if err := yaml.Unmarshal(b, &f); err != nil {
+		t.Fatalf("invalid rules file: %v", err)
+	}
+	if !strings.HasPrefix(string(b), "# Generated by statuspage-exporter") || !reflect.DeepEqual(f, Generate(cfg)) {
+		t.Errorf("rules file does not round-trip:\n%s", b)
+	}
+
+	b, err = Render(cfg, FormatCRD)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var crd prometheusRule
+	if err := yaml.Unmarshal(b, &crd); err != nil {
+		t.Fatalf("invalid resource: %v", err)
+	}
+	wantMeta := ruleMetadata{Name: "statuspage-exporter", Namespace: "monitoring", Labels: map[string]string{"release": "kube-prometheus"}}
+	if crd.APIVersion != "monitoring.coreos.com/v1" || crd.Kind != "PrometheusRule" || !reflect.DeepEqual(crd.Metadata, wantMeta) || len(crd.Spec.Groups) != 3 {
+		t.Errorf("unexpected resource %+v", crd)
+	}
+
+	if _, err := Render(cfg, "json"); err == nil {
+		t.Error("unknown format accepted")
+	}
+}
+
+func TestEscape(t *testing.T) {
+	if got, want := escape(`a "b" \c`+"\n"), `a \"b\" \\c\n`; got != want {
+		t.Errorf("escape = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,288 @@
+package server
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/conradoqg/statuspage-exporter/internal/collector"
+	"github.com/conradoqg/statuspage-exporter/internal/providers"
+)
+
+// Subset of Atlassian Statuspage's /api/v2/summary.json, enough for common
+// consumers. Every configured page becomes one component group.
+type spAggSummary struct {
+	Page                  spAggPage        `json:"page"`
+	Components            []spAggComponent `json:"components"`
+	Incidents             []spAggIncident  `json:"incidents"`
+	ScheduledMaintenances []spAggIncident  `json:"scheduled_maintenances"`
+	Status                spAggStatus      `json:"status"`
+}
+
+type spAggPage struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	URL       string    `json:"url"`
+	TimeZone  string    `json:"time_zone"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type spAggComponent struct {
+	ID                 string    `json:"id"`
+	Name               string    `json:"name"`
+	Status             string    `json:"status"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+	Position           int       `json:"position"`
+	Description        *string   `json:"description"`
+	Showcase           bool      `json:"showcase"`
+	StartDate          *string   `json:"start_date"`
+	GroupID            *string   `json:"group_id"`
+	PageID             string    `json:"page_id"`
+	Group              bool      `json:"group"`
+	OnlyShowIfDegraded bool      `json:"only_show_if_degraded"`
+	Components         []string  `json:"components,omitempty"`
+}
+
+type spAggIncident struct {
+	ID              string           `json:"id"`
+	Name            string           `json:"name"`
+	Status          string           `json:"status"`
+	CreatedAt       time.Time        `json:"created_at"`
+	UpdatedAt       time.Time        `json:"updated_at"`
+	MonitoringAt    *time.Time       `json:"monitoring_at"`
+	ResolvedAt      *time.Time       `json:"resolved_at"`
+	Impact          string           `json:"impact"`
+	Shortlink       string           `json:"shortlink"`
+	StartedAt       time.Time        `json:"started_at"`
+	ScheduledFor    *time.Time       `json:"scheduled_for,omitempty"`
+	ScheduledUntil  *time.Time       `json:"scheduled_until,omitempty"`
+	PageID          string           `json:"page_id"`
+	IncidentUpdates []any            `json:"incident_updates"`
+	Components      []spAggComponent `json:"components"`
+}
+
+type spAggStatus struct {
+	Indicator   string `json:"indicator"`
+	Description string `json:"description"`
+}
+
+const aggregatePageID = "statuspage-exporter"
+
+func aggregateHandler(exp *collector.Exporter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		f := collector.ParseFilter(r.URL.Query())
+		now := time.Now().UTC()
+		scheme := "http"
+		if r.TLS != nil {
+			scheme = "https"
+		}
+		out := spAggSummary{
+			Page: spAggPage{
+				ID:        aggregatePageID,
+				Name:      "Aggregated vendor status",
+				URL:       scheme + "://" + r.Host,
+				TimeZone:  "Etc/UTC",
+				UpdatedAt: now,
+			},
+			Components:            make([]spAggComponent, 0),
+			Incidents:             make([]spAggIncident, 0),
+			ScheduledMaintenances: make([]spAggIncident, 0),
+		}
+		overall := providers.StatusOperational
+		for _, ps := range exp.Snapshot() {
+			if !exp.MatchState(f, ps) {
+				continue
+			}
+			comps, st := aggregateComponents(ps, len(out.Components), now)
+			out.Components = append(out.Components, comps...)
+			overall = collector.Worst(overall, st)
+			for _, inc := range ps.Incidents {
+				ai := aggregateIncident(ps, inc, now)
+				overall = collector.Worst(overall, inc.Severity)
+				if inc.Severity == providers.StatusUnderMaintenance {
+					out.ScheduledMaintenances = append(out.ScheduledMaintenances, ai)
+				} else {
+					out.Incidents = append(out.Incidents, ai)
+				}
+			}
+			for _, m := range ps.Maintenances {
+				out.ScheduledMaintenances = append(out.ScheduledMaintenances, aggregateMaintenance(ps, m, now))
+				if !m.StartedAt.After(now) {
+					overall = collector.Worst(overall, providers.StatusUnderMaintenance)
+				}
+			}
+		}
+		out.Status = aggregateIndicator(overall)
+		writeJSON(w, http.StatusOK, out)
+	}
+}
+
+// aggregateComponents returns the group component for ps followed by its
+// children, and the group's status.
+func aggregateComponents(ps collector.PageState, position int, now time.Time) ([]spAggComponent, providers.NormalizedStatus) {
+	groupID := aggregateID(ps.Provider, ps.Page)
+	updated := ps.FetchedAt
+	if updated.IsZero() {
+		updated = now
+	}
+	group := spAggComponent{
+		ID:        groupID,
+		Name:      ps.Page,
+		CreatedAt: updated,
+		UpdatedAt: updated,
+		Position:  position + 1,
+		PageID:    aggregatePageID,
+		Group:     true,
+	}
+	// A failing fetch is the exporter's problem, not a vendor outage: it is
+	// reported in the description and leaves the status alone
+	desc := ps.URL
+	if ps.Err != nil {
+		desc = strings.TrimSpace(desc + " (status unavailable: " + ps.Err.Error() + ")")
+	}
+	if desc != "" {
+		group.Description = &desc
+	}
+	groupStatus := providers.StatusOperational
+	children := make([]spAggComponent, 0, len(ps.Components))
+	for j, c := range ps.Components {
+		st := aggregateStatus(c)
+		groupStatus = collector.Worst(groupStatus, st)
+		name := c.Name
+		if c.Group != "" && !strings.HasPrefix(c.Name, c.Group) {
+			name = c.Group + " - " + c.Name
+		}
+		cu := c.UpdatedAt
+		if cu.IsZero() {
+			cu = updated
+		}
+		child := spAggComponent{
+			ID:        aggregateID(ps.Provider, ps.Page, c.Group, c.Region, c.Name),
+			Name:      name,
+			Status:    st.String(),
+			CreatedAt: cu,
+			UpdatedAt: cu,
+			Position:  j + 1,
+			GroupID:   &groupID,
+			PageID:    aggregatePageID,
+		}
+		if c.Description != "" {
+			desc := c.Description
+			child.Description = &desc
+		}
+		group.Components = append(group.Components, child.ID)
+		children = append(children, child)
+	}
+	group.Status = groupStatus.String()
+	return append([]spAggComponent{group}, children...), groupStatus
+}
+
+// aggregateStatus maps a component to one of Statuspage's five statuses.
+// Unknown has no equivalent and follows the effective up value; missing data
+// is published as degraded rather than as an outage.
+func aggregateStatus(c collector.ComponentState) providers.NormalizedStatus {
+	if c.Status != providers.StatusUnknown {
+		return c.Status
+	}
+	if c.Up {
+		return providers.StatusOperational
+	}
+	return providers.StatusDegraded
+}
+
+func aggregateIncident(ps collector.PageState, inc providers.Incident, now time.Time) spAggIncident {
+	started := inc.StartedAt
+	if started.IsZero() {
+		started = now
+	}
+	updated := inc.UpdatedAt
+	if updated.IsZero() {
+		updated = started
+	}
+	status := inc.Status
+	switch status {
+	case "investigating", "identified", "monitoring", "scheduled", "in_progress", "verifying":
+	default:
+		status = "investigating"
+	}
+	return spAggIncident{
+		ID:              aggregateID(ps.Provider, ps.Page, "incident", collector.IncidentKey(inc.ID, inc.Name)),
+		Name:            fmt.Sprintf("[%s] %s", ps.Page, inc.Name),
+		Status:          status,
+		CreatedAt:       started,
+		UpdatedAt:       updated,
+		Impact:          aggregateImpact(inc.Severity),
+		Shortlink:       inc.URL,
+		StartedAt:       started,
+		PageID:          aggregatePageID,
+		IncidentUpdates: make([]any, 0),
+		Components:      make([]spAggComponent, 0),
+	}
+}
+
+// aggregateMaintenance publishes an announced maintenance with its window;
+// it is in progress once its start has passed.
+func aggregateMaintenance(ps collector.PageState, m providers.Incident, now time.Time) spAggIncident {
+	ai := aggregateIncident(ps, m, now)
+	ai.ID = aggregateID(ps.Provider, ps.Page, "maintenance", collector.IncidentKey(m.ID, m.Name))
+	ai.Impact = aggregateImpact(providers.StatusUnderMaintenance)
+	if ai.Status != "scheduled" && ai.Status != "in_progress" && ai.Status != "verifying" {
+		ai.Status = "scheduled"
+		if !m.StartedAt.After(now) {
+			ai.Status = "in_progress"
+		}
+	}
+	if !m.StartedAt.IsZero() {
+		from := m.StartedAt
+		ai.ScheduledFor = &from
+	}
+	if !m.EndsAt.IsZero() {
+		until := m.EndsAt
+		ai.ScheduledUntil = &until
+	}
+	return ai
+}
+
+func aggregateImpact(st providers.NormalizedStatus) string {
+	switch st {
+	case providers.StatusUnderMaintenance:
+		return "maintenance"
+	case providers.StatusDegraded:
+		return "minor"
+	case providers.StatusPartialOutage:
+		return "major"
+	case providers.StatusMajorOutage:
+		return "critical"
+	default:
+		return "none"
+	}
+}
+
+func aggregateIndicator(st providers.NormalizedStatus) spAggStatus {
+	switch st {
+	case providers.StatusUnderMaintenance:
+		return spAggStatus{"maintenance", "Service Under Maintenance"}
+	case providers.StatusDegraded:
+		return spAggStatus{"minor", "Minor Service Outage"}
+	case providers.StatusPartialOutage:
+		return spAggStatus{"major", "Partial System Outage"}
+	case providers.StatusMajorOutage:
+		return spAggStatus{"critical", "Major System Outage"}
+	default:
+		return spAggStatus{"none", "All Systems Operational"}
+	}
+}
+
+// aggregateID derives a stable identifier from its parts, so consumers can
+// track components across restarts.
+func aggregateID(parts ...string) string {
+	h := fnv.New64a()
+	for _, p := range parts {
+		h.Write([]byte(p))
+		h.Write([]byte{0})
+	}
+	return fmt.Sprintf("%016x", h.Sum64())
+}
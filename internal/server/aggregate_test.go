@@ -0,0 +1,83 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestAggregateSummary(t *testing.T) {
+	srv, _, _ := newTestServer(t,
+		testPage{name: "github", policy: "down", summary: githubSummary},
+		testPage{name: "stripe", labels: "team: payments", summary: stripeSummary},
+		testPage{name: "broken"},
+	)
+
+	var out spAggSummary
+	if code := getJSON(t, srv.URL+"/aggregate/api/v2/summary.json", &out); code != http.StatusOK {
+		t.Fatalf("status %d", code)
+	}
+	if out.Status.Indicator != "major" {
+		t.Errorf("indicator %q, want major", out.Status.Indicator)
+	}
+
+	groups := make(map[string]spAggComponent)
+	children := make(map[string]spAggComponent)
+	for _, c := range out.Components {
+		if c.Group {
+			groups[c.Name] = c
+		} else {
+			children[c.Name] = c
+		}
+	}
+	tests := []struct {
+		name   string
+		comp   spAggComponent
+		status string
+	}{
+		{"group is the worst child", groups["github"], "partial_outage"},
+		{"group children", groups["stripe"], "degraded_performance"},
+		{"failed fetch leaves the status alone", groups["broken"], "operational"},
+		{"child named after its group", children["Core - Actions"], "partial_outage"},
+		{"unknown that is not up is degraded", children["Pages"], "degraded_performance"},
+		{"ungrouped child", children["Dashboard"], "degraded_performance"},
+	}
+	for _, tt := range tests {
+		if tt.comp.ID == "" {
+			t.Errorf("%s: component missing", tt.name)
+			continue
+		}
+		if tt.comp.Status != tt.status {
+			t.Errorf("%s: status %q, want %q", tt.name, tt.comp.Status, tt.status)
+		}
+	}
+	if d := groups["broken"].Description; d == nil || !strings.Contains(*d, "status unavailable: unexpected status: 503") {
+		t.Errorf("failing page description %v", d)
+	}
+	if g := groups["github"]; len(g.Components) != 3 || *children["Pages"].GroupID != g.ID {
+		t.Errorf("github group children %v", g.Components)
+	}
+
+	if len(out.Incidents) != 1 {
+		t.Fatalf("incidents %+v", out.Incidents)
+	}
+	if inc := out.Incidents[0]; inc.Name != "[github] Actions <delays>" || inc.Impact != "major" || inc.Status != "investigating" {
+		t.Errorf("unexpected incident %+v", inc)
+	}
+	if len(out.ScheduledMaintenances) != 1 {
+		t.Fatalf("maintenances %+v", out.ScheduledMaintenances)
+	}
+	if m := out.ScheduledMaintenances[0]; m.Status != "scheduled" || m.Impact != "maintenance" || m.ScheduledFor == nil || m.ScheduledUntil == nil {
+		t.Errorf("unexpected maintenance %+v", m)
+	}
+
+	// Filters select pages; IDs are stable across requests
+	var filtered spAggSummary
+	getJSON(t, srv.URL+"/aggregate/api/v2/summary.json?label.team=payments", &filtered)
+	if len(filtered.Components) != 3 || filtered.Components[0].ID != groups["stripe"].ID {
+		t.Errorf("filtered components %+v", filtered.Components)
+	}
+	if filtered.Status.Indicator != "minor" || len(filtered.Incidents) != 0 {
+		t.Errorf("filtered status %+v, incidents %+v", filtered.Status, filtered.Incidents)
+	}
+}
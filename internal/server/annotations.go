@@ -0,0 +1,325 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/conradoqg/statuspage-exporter/internal/collector"
+	"github.com/conradoqg/statuspage-exporter/internal/events"
+	"github.com/conradoqg/statuspage-exporter/internal/journal"
+	"github.com/conradoqg/statuspage-exporter/internal/providers"
+)
+
+// annotation is a Grafana annotation, in the shape the JSON datasource
+// expects; the extra fields help Infinity users build their own columns.
+type annotation struct {
+	Time     int64    `json:"time"`
+	TimeEnd  int64    `json:"timeEnd"`
+	Title    string   `json:"title"`
+	Text     string   `json:"text"`
+	Tags     []string `json:"tags"`
+	Kind     string   `json:"kind"`
+	Provider string   `json:"provider"`
+	Page     string   `json:"page"`
+	Severity string   `json:"severity"`
+	URL      string   `json:"url,omitempty"`
+	Ongoing  bool     `json:"ongoing"`
+}
+
+const (
+	kindIncident    = "incident"
+	kindMaintenance = "maintenance"
+)
+
+// annotationQuery selects annotations; empty fields match everything. All
+// tags must be present.
+type annotationQuery struct {
+	Pages []string
+	Tags  []string
+	From  time.Time
+	To    time.Time
+}
+
+// span is an incident or maintenance window assembled from the journal and
+// the current page state.
+type span struct {
+	kind     string
+	provider string
+	page     string
+	id       string
+	labels   map[string]string
+	inc      events.Incident
+	start    time.Time
+	end      time.Time
+	ongoing  bool
+}
+
+var annotationEvents = []string{
+	string(events.IncidentOpened),
+	string(events.IncidentResolved),
+	string(events.MaintenanceScheduled),
+	string(events.MaintenanceCompleted),
+}
+
+// annotationsHandler serves annotations for the Infinity datasource (or any
+// HTTP client): ?page=, ?tag= (repeatable), ?from= and ?to=.
+func annotationsHandler(exp *collector.Exporter, j *journal.Journal) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		now := time.Now()
+		q, err := parseAnnotationQuery(r.URL.Query(), now)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, buildAnnotations(exp, j, q, now))
+	}
+}
+
+// grafanaHandler implements the subset of the JSON datasource protocol used
+// for annotations: the connection test on / and POST /annotations, whose
+// query text uses the same parameters as /api/v1/annotations
+// (e.g. page=github&tag=team:payments).
+func grafanaHandler(exp *collector.Exporter, j *journal.Journal) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch strings.TrimPrefix(r.URL.Path, "/grafana") {
+		case "/", "":
+			w.WriteHeader(http.StatusOK)
+		case "/annotations":
+			var req struct {
+				Range struct {
+					From time.Time `json:"from"`
+					To   time.Time `json:"to"`
+				} `json:"range"`
+				Annotation struct {
+					Query string `json:"query"`
+				} `json:"annotation"`
+			}
+			if r.Method == http.MethodPost {
+				if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+					writeError(w, http.StatusBadRequest, "invalid request: "+err.Error())
+					return
+				}
+			}
+			now := time.Now()
+			params, err := url.ParseQuery(strings.TrimSpace(req.Annotation.Query))
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "invalid annotation query: "+err.Error())
+				return
+			}
+			q, err := parseAnnotationQuery(params, now)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			if !req.Range.From.IsZero() {
+				q.From = req.Range.From
+			}
+			if !req.Range.To.IsZero() {
+				q.To = req.Range.To
+			}
+			writeJSON(w, http.StatusOK, buildAnnotations(exp, j, q, now))
+		default:
+			http.NotFound(w, r)
+		}
+	}
+}
+
+func parseAnnotationQuery(v url.Values, now time.Time) (annotationQuery, error) {
+	q := annotationQuery{Pages: splitValues(v["page"]), Tags: splitValues(v["tag"])}
+	var err error
+	if q.From, err = parseTimeParam(v.Get("from"), now); err != nil {
+		return q, fmt.Errorf("invalid from: %w", err)
+	}
+	if q.To, err = parseTimeParam(v.Get("to"), now); err != nil {
+		return q, fmt.Errorf("invalid to: %w", err)
+	}
+	return q, nil
+}
+
+// splitValues flattens repeated and comma-separated values.
+func splitValues(vs []string) []string {
+	var out []string
+	for _, v := range vs {
+		for _, s := range strings.Split(v, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				out = append(out, s)
+			}
+		}
+	}
+	return out
+}
+
+// buildAnnotations replays the journal's incident and maintenance events,
+// then adds what is open right now: incidents open since before the journal
+// began and maintenances announced before startup.
+func buildAnnotations(exp *collector.Exporter, j *journal.Journal, q annotationQuery, now time.Time) []annotation {
+	spans := make(map[string]*span)
+	var order []string
+	get := func(kind, page, id string) (*span, bool) {
+		key := kind + "\x00" + page + "\x00" + id
+		if s, ok := spans[key]; ok {
+			return s, true
+		}
+		s := &span{kind: kind, page: page, id: id}
+		spans[key] = s
+		order = append(order, key)
+		return s, false
+	}
+
+	for _, ev := range j.Query(journal.Query{Pages: q.Pages, Types: annotationEvents}) {
+		if ev.Incident == nil {
+			continue
+		}
+		kind := kindIncident
+		if ev.Type == events.MaintenanceScheduled || ev.Type == events.MaintenanceCompleted {
+			kind = kindMaintenance
+		}
+		s, seen := get(kind, ev.Page, collector.IncidentKey(ev.Incident.ID, ev.Incident.Name))
+		s.provider, s.labels, s.inc = ev.Provider, ev.Labels, *ev.Incident
+		switch ev.Type {
+		case events.IncidentOpened:
+			s.start, s.end, s.ongoing = ev.Incident.StartedAt, time.Time{}, true
+			if s.start.IsZero() || s.start.After(ev.Time) {
+				s.start = ev.Time
+			}
+		case events.IncidentResolved:
+			if !seen {
+				s.start = ev.Time.Add(-time.Duration(ev.DurationSeconds * float64(time.Second)))
+			}
+			s.end, s.ongoing = ev.Time, false
+		case events.MaintenanceScheduled:
+			s.start, s.end, s.ongoing = ev.Incident.StartedAt, ev.Incident.EndsAt, true
+		case events.MaintenanceCompleted:
+			s.start, s.end, s.ongoing = ev.Incident.StartedAt, ev.Incident.EndsAt, false
+			if s.start.After(ev.Time) {
+				// Withdrawn before it started
+				s.start = time.Time{}
+			}
+		}
+	}
+
+	for _, ps := range exp.Snapshot() {
+		if (len(q.Pages) > 0 && !containsString(q.Pages, ps.Page)) || ps.Err != nil || ps.FetchedAt.IsZero() {
+			continue
+		}
+		open := make(map[string]bool)
+		for _, inc := range ps.Incidents {
+			s, seen := get(kindIncident, ps.Page, collector.IncidentKey(inc.ID, inc.Name))
+			s.provider, s.labels, s.inc, s.ongoing = ps.Provider, ps.Labels, *events.NewIncident(inc), true
+			if !seen {
+				s.start = inc.StartedAt
+			}
+			if s.start.IsZero() {
+				s.start = ps.FetchedAt
+			}
+			open[kindIncident+"\x00"+collector.IncidentKey(inc.ID, inc.Name)] = true
+		}
+		for _, m := range ps.Maintenances {
+			s, _ := get(kindMaintenance, ps.Page, collector.IncidentKey(m.ID, m.Name))
+			s.provider, s.labels, s.inc, s.ongoing = ps.Provider, ps.Labels, *events.NewIncident(m), true
+			s.start, s.end = m.StartedAt, m.EndsAt
+			open[kindMaintenance+"\x00"+collector.IncidentKey(m.ID, m.Name)] = true
+		}
+		// Journal entries still open that the vendor no longer reports ended
+		// while the exporter was down; the first fetch after the restart is
+		// the earliest time they are known to be over.
+		for _, key := range order {
+			s := spans[key]
+			if s.page == ps.Page && s.ongoing && !open[s.kind+"\x00"+s.id] {
+				s.ongoing = false
+				if s.kind == kindIncident {
+					s.end = ps.BaselineAt
+				}
+			}
+		}
+	}
+
+	out := make([]annotation, 0, len(order))
+	for _, key := range order {
+		s := spans[key]
+		if s.start.IsZero() {
+			continue
+		}
+		end := s.end
+		switch {
+		case s.ongoing && s.kind == kindIncident:
+			end = now
+		case end.IsZero() || end.Before(s.start):
+			end = s.start
+		}
+		if (!q.From.IsZero() && end.Before(q.From)) || (!q.To.IsZero() && s.start.After(q.To)) {
+			continue
+		}
+		a := s.annotation(end)
+		if !hasTags(a.Tags, q.Tags) {
+			continue
+		}
+		out = append(out, a)
+	}
+	sort.SliceStable(out, func(a, b int) bool { return out[a].Time < out[b].Time })
+	return out
+}
+
+func (s *span) annotation(end time.Time) annotation {
+	sev, _ := providers.ParseStatus(s.inc.Severity)
+	title := html.EscapeString(s.page + ": " + s.inc.Name)
+	if s.kind == kindMaintenance {
+		title = html.EscapeString(s.page + " maintenance: " + s.inc.Name)
+	}
+	var text []string
+	if s.inc.Status != "" {
+		text = append(text, "Status: "+html.EscapeString(s.inc.Status))
+	}
+	if s.inc.Impact != "" {
+		text = append(text, "Impact: "+html.EscapeString(s.inc.Impact))
+	}
+	if s.inc.URL != "" {
+		u := html.EscapeString(s.inc.URL)
+		text = append(text, `<a href="`+u+`">`+u+`</a>`)
+	}
+
+	tags := make([]string, 0, len(s.labels)+4)
+	for k, v := range s.labels {
+		tags = append(tags, k+":"+v)
+	}
+	sort.Strings(tags)
+	tags = append(tags, s.kind, "provider:"+s.provider, "page:"+s.page, "severity:"+sev.String())
+
+	return annotation{
+		Time:     s.start.UnixMilli(),
+		TimeEnd:  end.UnixMilli(),
+		Title:    title,
+		Text:     strings.Join(text, "<br>"),
+		Tags:     tags,
+		Kind:     s.kind,
+		Provider: s.provider,
+		Page:     s.page,
+		Severity: sev.String(),
+		URL:      s.inc.URL,
+		Ongoing:  s.ongoing,
+	}
+}
+
+func hasTags(tags, want []string) bool {
+	for _, w := range want {
+		if !containsString(tags, w) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsString(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,158 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/conradoqg/statuspage-exporter/internal/events"
+	"github.com/conradoqg/statuspage-exporter/internal/providers"
+)
+
+func annotationTitles(as []annotation) []string {
+	out := make([]string, 0, len(as))
+	for _, a := range as {
+		out = append(out, a.Title)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func TestAnnotations(t *testing.T) {
+	srv, exp, j := newTestServer(t,
+		testPage{name: "github", summary: githubSummary},
+		testPage{name: "stripe", labels: "team: payments", summary: stripeSummary},
+	)
+	t0 := time.Now().Add(-3 * time.Hour).Truncate(time.Millisecond)
+	payments := map[string]string{"env": "test", "team": "payments"}
+	for _, ev := range []events.Event{
+		// Still open in the journal but no longer reported by the vendor
+		{Type: events.IncidentOpened, Time: t0, Provider: "statuspage", Page: "github", Severity: providers.StatusMajorOutage,
+			Incident: &events.Incident{ID: "old", Name: "Webhooks down", Severity: "major_outage", StartedAt: t0}},
+		{Type: events.IncidentOpened, Time: t0.Add(time.Hour), Provider: "statuspage", Page: "stripe", Labels: payments,
+			Severity: providers.StatusDegraded, Incident: &events.Incident{ID: "s-inc", Name: "Slow payouts", Severity: "degraded_performance"}},
+		{Type: events.IncidentResolved, Time: t0.Add(2 * time.Hour), Provider: "statuspage", Page: "stripe", Labels: payments,
+			Severity: providers.StatusDegraded, Incident: &events.Incident{ID: "s-inc", Name: "Slow payouts", Severity: "degraded_performance"}},
+	} {
+		j.Append(exp.Events().Publish(ev))
+	}
+	baseline := exp.Snapshot()[0].BaselineAt
+
+	var all []annotation
+	if code := getJSON(t, srv.URL+"/api/v1/annotations", &all); code != http.StatusOK {
+		t.Fatalf("status %d", code)
+	}
+	byTitle := make(map[string]annotation)
+	for _, a := range all {
+		byTitle[a.Title] = a
+	}
+	if got, want := annotationTitles(all), []string{
+		"github maintenance: Database upgrade",
+		"github: Actions &lt;delays&gt;",
+		"github: Webhooks down",
+		"stripe: Slow payouts",
+	}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("titles %q, want %q", got, want)
+	}
+
+	tests := []struct {
+		title      string
+		start, end time.Time
+		ongoing    bool
+		endsNow    bool
+	}{
+		{title: "github: Webhooks down", start: t0, end: baseline},
+		{title: "stripe: Slow payouts", start: t0.Add(time.Hour), end: t0.Add(2 * time.Hour)},
+		{title: "github: Actions &lt;delays&gt;", start: time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC), ongoing: true, endsNow: true},
+		{title: "github maintenance: Database upgrade", start: time.Date(2099, 1, 1, 0, 0, 0, 0, time.UTC), end: time.Date(2099, 1, 1, 2, 0, 0, 0, time.UTC), ongoing: true},
+	}
+	for _, tt := range tests {
+		a := byTitle[tt.title]
+		if a.Time != tt.start.UnixMilli() || a.Ongoing != tt.ongoing {
+			t.Errorf("%s: time %d ongoing %v, want %d %v", tt.title, a.Time, a.Ongoing, tt.start.UnixMilli(), tt.ongoing)
+		}
+		// Ongoing incidents end now
+		if tt.endsNow {
+			if a.TimeEnd < time.Now().Add(-time.Minute).UnixMilli() {
+				t.Errorf("%s: ongoing incident ends at %d", tt.title, a.TimeEnd)
+			}
+		} else if a.TimeEnd != tt.end.UnixMilli() {
+			t.Errorf("%s: timeEnd %d, want %d", tt.title, a.TimeEnd, tt.end.UnixMilli())
+		}
+	}
+	if a := byTitle["stripe: Slow payouts"]; !reflect.DeepEqual(a.Tags, []string{"env:test", "team:payments", "incident", "provider:statuspage", "page:stripe", "severity:degraded_performance"}) {
+		t.Errorf("tags %q", a.Tags)
+	}
+
+	queries := []struct {
+		query string
+		want  []string
+	}{
+		{"?page=stripe", []string{"stripe: Slow payouts"}},
+		{"?tag=team:payments", []string{"stripe: Slow payouts"}},
+		{"?tag=maintenance", []string{"github maintenance: Database upgrade"}},
+		{"?tag=incident,severity:major_outage", []string{"github: Webhooks down"}},
+		{"?from=" + t0.Add(150*time.Minute).Format(time.RFC3339) + "&to=2050-01-01T00:00:00Z", []string{"github: Actions &lt;delays&gt;", "github: Webhooks down"}},
+	}
+	for _, tt := range queries {
+		t.Run(tt.query, func(t *testing.T) {
+			var as []annotation
+			if code := getJSON(t, srv.URL+"/api/v1/annotations"+tt.query, &as); code != http.StatusOK {
+				t.Fatalf("status %d", code)
+			}
+			if got := annotationTitles(as); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	var e map[string]string
+	if code := getJSON(t, srv.URL+"/api/v1/annotations?from=soon", &e); code != http.StatusBadRequest {
+		t.Errorf("invalid from: status %d, want 400", code)
+	}
+}
+
+func TestGrafanaAnnotations(t *testing.T) {
+	srv, _, _ := newTestServer(t,
+		testPage{name: "github", summary: githubSummary},
+		testPage{name: "stripe", summary: stripeSummary},
+	)
+
+	resp, err := http.Get(srv.URL + "/grafana/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("connection test: status %d", resp.StatusCode)
+	}
+
+	body := `{"range": {"from": "2024-01-01T00:00:00Z", "to": "2030-01-01T00:00:00Z"}, "annotation": {"query": "page=github"}}`
+	resp, err = http.Post(srv.URL+"/grafana/annotations", "application/json", bytes.NewBufferString(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var as []annotation
+	err = json.NewDecoder(resp.Body).Decode(&as)
+	resp.Body.Close()
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("status %d: %v", resp.StatusCode, err)
+	}
+	// The maintenance in 2099 is outside the range
+	if got, want := annotationTitles(as), []string{"github: Actions &lt;delays&gt;"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	resp, err = http.Post(srv.URL+"/grafana/annotations", "application/json", bytes.NewBufferString("{"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("invalid body: status %d, want 400", resp.StatusCode)
+	}
+}
@@ -0,0 +1,211 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/conradoqg/statuspage-exporter/internal/collector"
+	"github.com/conradoqg/statuspage-exporter/internal/logx"
+	"github.com/conradoqg/statuspage-exporter/internal/providers"
+)
+
+// JSON views of collector.PageState; field names are part of the public API.
+type pageJSON struct {
+	Provider             string            `json:"provider"`
+	Page                 string            `json:"page"`
+	URL                  string            `json:"url"`
+	Labels               map[string]string `json:"labels,omitempty"`
+	Status               string            `json:"status"`
+	OK                   bool              `json:"ok"`
+	Error                string            `json:"error,omitempty"`
+	LastFetch            *time.Time        `json:"last_fetch,omitempty"`
+	FetchDurationSeconds float64           `json:"fetch_duration_seconds"`
+	VendorUpdatedAt      *time.Time        `json:"vendor_updated_at,omitempty"`
+	OpenIncidents        int               `json:"open_incidents"`
+	Components           []componentJSON   `json:"components"`
+	Incidents            []incidentJSON    `json:"incidents"`
+	Maintenances         []incidentJSON    `json:"maintenances"`
+}
+
+type componentJSON struct {
+	Provider        string     `json:"provider,omitempty"`
+	Page            string     `json:"page,omitempty"`
+	Name            string     `json:"name"`
+	Group           string     `json:"group,omitempty"`
+	Region          string     `json:"region,omitempty"`
+	CanonicalRegion string     `json:"canonical_region,omitempty"`
+	Continent       string     `json:"continent,omitempty"`
+	Status          string     `json:"status"`
+	Up              bool       `json:"up"`
+	RawStatus       string     `json:"raw_status,omitempty"`
+	ID              string     `json:"id,omitempty"`
+	Description     string     `json:"description,omitempty"`
+	UpdatedAt       *time.Time `json:"updated_at,omitempty"`
+	Since           *time.Time `json:"since,omitempty"`
+}
+
+type incidentJSON struct {
+	ID        string     `json:"id,omitempty"`
+	Name      string     `json:"name"`
+	Status    string     `json:"status,omitempty"`
+	Impact    string     `json:"impact,omitempty"`
+	Severity  string     `json:"severity"`
+	URL       string     `json:"url,omitempty"`
+	StartedAt *time.Time `json:"started_at,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+	EndsAt    *time.Time `json:"ends_at,omitempty"`
+}
+
+func registerAPI(mux *http.ServeMux, exp *collector.Exporter) {
+	mux.HandleFunc("/api/v1/pages", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		f, statuses := collector.ParseFilter(q), statusSet(q)
+		out := make([]pageJSON, 0)
+		for _, ps := range exp.Snapshot() {
+			if !exp.MatchState(f, ps) || !statusMatch(statuses, ps.Worst()) {
+				continue
+			}
+			out = append(out, toPageJSON(ps))
+		}
+		writeJSON(w, http.StatusOK, out)
+	})
+	mux.HandleFunc("/api/v1/pages/", func(w http.ResponseWriter, r *http.Request) {
+		name, err := url.PathUnescape(strings.TrimPrefix(r.URL.EscapedPath(), "/api/v1/pages/"))
+		if err != nil || name == "" || strings.Contains(name, "/") {
+			writeError(w, http.StatusNotFound, "page not found")
+			return
+		}
+		ps, ok := exp.PageStateByName(name)
+		if !ok {
+			writeError(w, http.StatusNotFound, "page not found")
+			return
+		}
+		writeJSON(w, http.StatusOK, toPageJSON(ps))
+	})
+	mux.HandleFunc("/api/v1/components", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		f, statuses := collector.ParseFilter(q), statusSet(q)
+		out := make([]componentJSON, 0)
+		for _, ps := range exp.Snapshot() {
+			if !exp.MatchState(f, ps) {
+				continue
+			}
+			for _, c := range ps.Components {
+				if !statusMatch(statuses, c.Status) {
+					continue
+				}
+				cj := toComponentJSON(c)
+				cj.Provider, cj.Page = ps.Provider, ps.Page
+				out = append(out, cj)
+			}
+		}
+		writeJSON(w, http.StatusOK, out)
+	})
+}
+
+// statusSet reads repeatable ?status= parameters (normalized status names).
+func statusSet(q url.Values) map[string]struct{} {
+	if len(q["status"]) == 0 {
+		return nil
+	}
+	out := make(map[string]struct{})
+	for _, s := range q["status"] {
+		for _, v := range strings.Split(s, ",") {
+			out[strings.TrimSpace(v)] = struct{}{}
+		}
+	}
+	return out
+}
+
+func statusMatch(set map[string]struct{}, st providers.NormalizedStatus) bool {
+	if set == nil {
+		return true
+	}
+	_, ok := set[st.String()]
+	return ok
+}
+
+func toPageJSON(ps collector.PageState) pageJSON {
+	out := pageJSON{
+		Provider:             ps.Provider,
+		Page:                 ps.Page,
+		URL:                  ps.URL,
+		Labels:               ps.Labels,
+		Status:               ps.Worst().String(),
+		OK:                   ps.Err == nil && !ps.FetchedAt.IsZero(),
+		LastFetch:            timePtr(ps.FetchedAt),
+		FetchDurationSeconds: ps.Duration.Seconds(),
+		VendorUpdatedAt:      timePtr(ps.UpdatedAt),
+		OpenIncidents:        ps.OpenIncidents,
+		Components:           make([]componentJSON, 0, len(ps.Components)),
+		Incidents:            make([]incidentJSON, 0, len(ps.Incidents)),
+		Maintenances:         make([]incidentJSON, 0, len(ps.Maintenances)),
+	}
+	if ps.Err != nil {
+		out.Error = ps.Err.Error()
+	}
+	for _, c := range ps.Components {
+		out.Components = append(out.Components, toComponentJSON(c))
+	}
+	for _, inc := range ps.Incidents {
+		out.Incidents = append(out.Incidents, toIncidentJSON(inc))
+	}
+	for _, m := range ps.Maintenances {
+		out.Maintenances = append(out.Maintenances, toIncidentJSON(m))
+	}
+	return out
+}
+
+func toComponentJSON(c collector.ComponentState) componentJSON {
+	return componentJSON{
+		Name:            c.Name,
+		Group:           c.Group,
+		Region:          c.Region,
+		CanonicalRegion: c.CanonicalRegion,
+		Continent:       c.Continent,
+		Status:          c.Status.String(),
+		Up:              c.Up,
+		RawStatus:       c.RawStatus,
+		ID:              c.ID,
+		Description:     c.Description,
+		UpdatedAt:       timePtr(c.UpdatedAt),
+		Since:           timePtr(c.Since),
+	}
+}
+
+func toIncidentJSON(inc providers.Incident) incidentJSON {
+	return incidentJSON{
+		ID:        inc.ID,
+		Name:      inc.Name,
+		Status:    inc.Status,
+		Impact:    inc.Impact,
+		Severity:  inc.Severity.String(),
+		URL:       inc.URL,
+		StartedAt: timePtr(inc.StartedAt),
+		UpdatedAt: timePtr(inc.UpdatedAt),
+		EndsAt:    timePtr(inc.EndsAt),
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	t = t.UTC()
+	return &t
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logx.Debugf("write json response: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, code int, msg string) {
+	writeJSON(w, code, map[string]string{"error": msg})
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestPagesAPI(t *testing.T) {
+	srv, _, _ := newTestServer(t,
+		testPage{name: "github", labels: "team: dev", summary: githubSummary},
+		testPage{name: "stripe", labels: "team: payments", summary: stripeSummary},
+		testPage{name: "broken"},
+	)
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{"broken", "github", "stripe"}},
+		{"?page=stripe", []string{"stripe"}},
+		{"?page=stripe&page=github", []string{"github", "stripe"}},
+		{"?provider=statuspage", []string{"broken", "github", "stripe"}},
+		{"?provider=gcp", []string{}},
+		{"?label.team=payments", []string{"stripe"}},
+		{"?label.env=test&label.team=dev", []string{"github"}},
+		{"?status=partial_outage", []string{"github"}},
+		{"?status=degraded_performance,unknown", []string{"broken", "stripe"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			var pages []pageJSON
+			if code := getJSON(t, srv.URL+"/api/v1/pages"+tt.query, &pages); code != http.StatusOK {
+				t.Fatalf("status %d", code)
+			}
+			got := make([]string, 0, len(pages))
+			for _, p := range pages {
+				got = append(got, p.Page)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPageAPI(t *testing.T) {
+	srv, _, _ := newTestServer(t,
+		testPage{name: "github", summary: githubSummary},
+		testPage{name: "broken"},
+	)
+
+	var p pageJSON
+	if code := getJSON(t, srv.URL+"/api/v1/pages/github", &p); code != http.StatusOK {
+		t.Fatalf("status %d", code)
+	}
+	if !p.OK || p.Status != "partial_outage" || p.OpenIncidents != 1 || len(p.Components) != 3 {
+		t.Errorf("unexpected page %+v", p)
+	}
+	if len(p.Incidents) != 1 || p.Incidents[0].ID != "inc1" || len(p.Maintenances) != 1 || p.Maintenances[0].EndsAt == nil {
+		t.Errorf("unexpected incidents %+v / maintenances %+v", p.Incidents, p.Maintenances)
+	}
+
+	if code := getJSON(t, srv.URL+"/api/v1/pages/broken", &p); code != http.StatusOK {
+		t.Fatalf("status %d", code)
+	}
+	if p.OK || p.Error == "" || p.LastFetch == nil {
+		t.Errorf("unexpected failing page %+v", p)
+	}
+
+	var e map[string]string
+	for _, path := range []string{"/api/v1/pages/missing", "/api/v1/pages/github/components"} {
+		if code := getJSON(t, srv.URL+path, &e); code != http.StatusNotFound {
+			t.Errorf("%s: status %d, want 404", path, code)
+		}
+	}
+}
+
+func TestComponentsAPI(t *testing.T) {
+	srv, _, _ := newTestServer(t,
+		testPage{name: "github", summary: githubSummary},
+		testPage{name: "stripe", policy: "omit", summary: stripeSummary},
+	)
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"?page=stripe", []string{"stripe/Dashboard", "stripe/Payments"}},
+		{"?status=operational", []string{"github/API Requests", "stripe/Payments"}},
+		{"?status=unknown", []string{"github/Pages"}},
+		{"?page=github&status=partial_outage,degraded_performance", []string{"github/Actions"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			var comps []componentJSON
+			if code := getJSON(t, srv.URL+"/api/v1/components"+tt.query, &comps); code != http.StatusOK {
+				t.Fatalf("status %d", code)
+			}
+			got := make([]string, 0, len(comps))
+			for _, c := range comps {
+				got = append(got, c.Page+"/"+c.Name)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
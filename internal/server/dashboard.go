@@ -0,0 +1,128 @@
+package server
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"net/http"
+	"time"
+
+	"github.com/conradoqg/statuspage-exporter/internal/collector"
+	"github.com/conradoqg/statuspage-exporter/internal/logx"
+	"github.com/conradoqg/statuspage-exporter/internal/providers"
+)
+
+//go:embed templates/dashboard.html
+var templateFS embed.FS
+
+var dashboardTmpl = template.Must(template.ParseFS(templateFS, "templates/dashboard.html"))
+
+// dashboardRefresh is how often the HTML dashboard reloads itself.
+const dashboardRefresh = 30 * time.Second
+
+type dashboardData struct {
+	Now            time.Time
+	RefreshSeconds int
+	Summary        []statusCount
+	Pages          []dashboardPage
+}
+
+type statusCount struct {
+	Status string
+	Count  int
+}
+
+type dashboardPage struct {
+	collector.PageState
+	Status        string
+	Age           string
+	FetchDuration string
+	Groups        []dashboardGroup
+}
+
+type dashboardGroup struct {
+	Name       string
+	Status     string
+	Open       bool
+	Components []collector.ComponentState
+}
+
+// dashboardHandler renders a self-contained HTML overview of every page.
+func dashboardHandler(exp *collector.Exporter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		now := time.Now()
+		data := dashboardData{Now: now, RefreshSeconds: int(dashboardRefresh.Seconds())}
+		counts := make(map[providers.NormalizedStatus]int)
+		for _, ps := range exp.Snapshot() {
+			worst := ps.Worst()
+			counts[worst]++
+			data.Pages = append(data.Pages, dashboardPage{
+				PageState:     ps,
+				Status:        worst.String(),
+				Age:           humanizeAge(now.Sub(ps.FetchedAt)),
+				FetchDuration: ps.Duration.Round(time.Millisecond).String(),
+				Groups:        groupComponents(ps.Components),
+			})
+		}
+		for st := providers.StatusMajorOutage; st >= providers.StatusUnknown; st-- {
+			if counts[st] > 0 {
+				data.Summary = append(data.Summary, statusCount{Status: st.String(), Count: counts[st]})
+			}
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := dashboardTmpl.Execute(w, data); err != nil {
+			logx.Warnf("render dashboard: %v", err)
+		}
+	}
+}
+
+// groupComponents buckets components by group and region, keeping vendor order.
+// Groups with a non-operational component are expanded by default.
+func groupComponents(comps []collector.ComponentState) []dashboardGroup {
+	var out []dashboardGroup
+	index := make(map[string]int)
+	worstBy := make(map[string]providers.NormalizedStatus)
+	for _, c := range comps {
+		name := c.Group
+		if c.Region != "" {
+			if name != "" {
+				name += " / "
+			}
+			name += c.Region
+		}
+		if name == "" {
+			name = "Components"
+		}
+		j, ok := index[name]
+		if !ok {
+			j = len(out)
+			index[name] = j
+			out = append(out, dashboardGroup{Name: name})
+		}
+		out[j].Components = append(out[j].Components, c)
+		if c.Status > worstBy[name] {
+			worstBy[name] = c.Status
+		}
+	}
+	for j := range out {
+		st := worstBy[out[j].Name]
+		out[j].Status = st.String()
+		out[j].Open = st != providers.StatusOperational
+	}
+	return out
+}
+
+func humanizeAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+	default:
+		return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+}
@@ -0,0 +1,98 @@
+package server
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/conradoqg/statuspage-exporter/internal/events"
+	"github.com/conradoqg/statuspage-exporter/internal/journal"
+	"github.com/conradoqg/statuspage-exporter/internal/logx"
+)
+
+var csvHeader = []string{
+	"id", "time", "type", "provider", "page", "component", "group", "region",
+	"from", "to", "severity", "duration_seconds",
+	"incident_id", "incident_name", "incident_status", "incident_url", "error",
+}
+
+// historyHandler serves journal entries as JSON, or CSV with ?format=csv (or
+// Accept: text/csv).
+func historyHandler(j *journal.Journal) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		now := time.Now()
+		jq := journal.Query{Pages: q["page"], Components: q["component"], Types: q["type"]}
+		var err error
+		if jq.Since, err = parseTimeParam(q.Get("since"), now); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid since: "+err.Error())
+			return
+		}
+		if jq.Until, err = parseTimeParam(q.Get("until"), now); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid until: "+err.Error())
+			return
+		}
+		if s := q.Get("limit"); s != "" {
+			if jq.Limit, err = strconv.Atoi(s); err != nil || jq.Limit < 0 {
+				writeError(w, http.StatusBadRequest, "invalid limit")
+				return
+			}
+		}
+		out := j.Query(jq)
+		if q.Get("format") == "csv" || (q.Get("format") == "" && strings.Contains(r.Header.Get("Accept"), "text/csv")) {
+			writeCSV(w, out)
+			return
+		}
+		writeJSON(w, http.StatusOK, out)
+	}
+}
+
+// parseTimeParam accepts RFC 3339, Unix seconds or milliseconds (as Grafana
+// sends) or a duration before now (e.g. 24h).
+func parseTimeParam(s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		// Seconds would not reach this until the year 5138
+		if n >= 1e11 {
+			return time.UnixMilli(n), nil
+		}
+		return time.Unix(n, 0), nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("want RFC 3339, Unix seconds, Unix milliseconds or a duration, got %q", s)
+}
+
+func writeCSV(w http.ResponseWriter, evs []events.Event) {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="statuspage-events.csv"`)
+	cw := csv.NewWriter(w)
+	rows := [][]string{csvHeader}
+	for _, ev := range evs {
+		var incID, incName, incStatus, incURL, dur string
+		if ev.Incident != nil {
+			incID, incName, incStatus, incURL = ev.Incident.ID, ev.Incident.Name, ev.Incident.Status, ev.Incident.URL
+		}
+		if ev.DurationSeconds > 0 {
+			dur = strconv.FormatFloat(ev.DurationSeconds, 'f', 0, 64)
+		}
+		rows = append(rows, []string{
+			strconv.FormatUint(ev.ID, 10), ev.Time.UTC().Format(time.RFC3339), string(ev.Type),
+			ev.Provider, ev.Page, ev.Component, ev.Group, ev.Region,
+			ev.From, ev.To, ev.Severity.String(), dur,
+			incID, incName, incStatus, incURL, ev.Error,
+		})
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		logx.Debugf("write csv response: %v", err)
+	}
+}
@@ -0,0 +1,140 @@
+package server
+
+import (
+	"encoding/csv"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/conradoqg/statuspage-exporter/internal/config"
+	"github.com/conradoqg/statuspage-exporter/internal/events"
+	"github.com/conradoqg/statuspage-exporter/internal/journal"
+	"github.com/conradoqg/statuspage-exporter/internal/providers"
+)
+
+func TestParseTimeParam(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in      string
+		want    time.Time
+		wantErr bool
+	}{
+		{"", time.Time{}, false},
+		{"2024-05-01T10:00:00Z", time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC), false},
+		{"1714557600", time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC), false},
+		{"1714557600000", time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC), false},
+		{"90m", time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC), false},
+		{"yesterday", time.Time{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseTimeParam(tt.in, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error %v, wantErr %v", err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHistoryHandler(t *testing.T) {
+	j, err := journal.Open(config.Journal{Retention: 24 * time.Hour, MaxEvents: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t0 := time.Now().Add(-3 * time.Hour).Truncate(time.Second)
+	j.Append(events.Event{ID: 1, Type: events.ComponentStatusChanged, Time: t0, Provider: "statuspage", Page: "github",
+		Component: "Actions", From: "operational", To: "partial_outage", Severity: providers.StatusPartialOutage})
+	j.Append(events.Event{ID: 2, Type: events.IncidentOpened, Time: t0.Add(time.Hour), Provider: "statuspage", Page: "github",
+		Severity: providers.StatusMajorOutage, Incident: &events.Incident{ID: "inc1", Name: "Actions, delayed", Status: "investigating"}})
+	j.Append(events.Event{ID: 3, Type: events.IncidentResolved, Time: t0.Add(2 * time.Hour), Provider: "statuspage", Page: "stripe",
+		DurationSeconds: 3600, Incident: &events.Incident{ID: "inc2"}})
+	srv := httptest.NewServer(historyHandler(j))
+	defer srv.Close()
+
+	tests := []struct {
+		query string
+		want  []uint64
+	}{
+		{"", []uint64{1, 2, 3}},
+		{"?page=github", []uint64{1, 2}},
+		{"?type=incident_opened&type=incident_resolved", []uint64{2, 3}},
+		{"?component=Actions", []uint64{1}},
+		{"?since=150m", []uint64{2, 3}},
+		{"?until=" + t0.Add(time.Hour).UTC().Format(time.RFC3339), []uint64{1, 2}},
+		{"?limit=1", []uint64{3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			var evs []events.Event
+			if code := getJSON(t, srv.URL+tt.query, &evs); code != http.StatusOK {
+				t.Fatalf("status %d", code)
+			}
+			if got := eventIDs(evs); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	for _, q := range []string{"?since=soon", "?until=later", "?limit=-1", "?limit=ten"} {
+		var e map[string]string
+		if code := getJSON(t, srv.URL+q, &e); code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", q, code)
+		}
+	}
+}
+
+func TestHistoryCSV(t *testing.T) {
+	j, err := journal.Open(config.Journal{Retention: 24 * time.Hour, MaxEvents: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := time.Now().UTC().Truncate(time.Second)
+	j.Append(events.Event{ID: 7, Type: events.IncidentResolved, Time: at, Provider: "statuspage", Page: "github",
+		Severity: providers.StatusMajorOutage, DurationSeconds: 3600.4,
+		Incident: &events.Incident{ID: "inc1", Name: "Actions, delayed", Status: "resolved", URL: "https://stspg.io/inc1"}})
+	srv := httptest.NewServer(historyHandler(j))
+	defer srv.Close()
+
+	for _, accept := range []string{"", "text/csv"} {
+		url := srv.URL
+		if accept == "" {
+			url += "?format=csv"
+		}
+		req, _ := http.NewRequest(http.MethodGet, url, nil)
+		req.Header.Set("Accept", accept)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows, err := csv.NewReader(resp.Body).ReadAll()
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+			t.Errorf("Content-Type %q", ct)
+		}
+		want := [][]string{csvHeader, {
+			"7", at.Format(time.RFC3339), "incident_resolved", "statuspage", "github", "", "", "",
+			"", "", "major_outage", "3600",
+			"inc1", "Actions, delayed", "resolved", "https://stspg.io/inc1", "",
+		}}
+		if !reflect.DeepEqual(rows, want) {
+			t.Errorf("Accept %q: got %q, want %q", accept, rows, want)
+		}
+	}
+}
+
+func eventIDs(evs []events.Event) []uint64 {
+	out := make([]uint64, 0, len(evs))
+	for _, ev := range evs {
+		out = append(out, ev.ID)
+	}
+	return out
+}
@@ -0,0 +1,48 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/conradoqg/statuspage-exporter/internal/collector"
+	"github.com/conradoqg/statuspage-exporter/internal/journal"
+	"github.com/conradoqg/statuspage-exporter/internal/logx"
+)
+
+// NewMux wires the exporter's HTTP endpoints.
+func NewMux(reg *prometheus.Registry, exp *collector.Exporter, j *journal.Journal) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metricsHandler(reg, exp))
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) })
+	registerAPI(mux, exp)
+	mux.Handle("/api/v1/events", historyHandler(j))
+	mux.Handle("/api/v1/events/stream", streamHandler(exp))
+	mux.Handle("/api/v1/annotations", annotationsHandler(exp, j))
+	mux.Handle("/grafana/", grafanaHandler(exp, j))
+	mux.Handle("/aggregate/api/v2/summary.json", aggregateHandler(exp))
+	mux.Handle("/", dashboardHandler(exp))
+	return mux
+}
+
+// metricsHandler serves the full registry, or only the pages selected by
+// ?page=, ?provider= and ?label.<name>= query parameters. Filtered scrapes
+// contain vendor page metrics only (no self-instrumentation or dependencies).
+func metricsHandler(reg *prometheus.Registry, exp *collector.Exporter) http.Handler {
+	full := promhttp.HandlerFor(reg, promhttp.HandlerOpts{})
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f := collector.ParseFilter(r.URL.Query())
+		if f.Empty() {
+			full.ServeHTTP(w, r)
+			return
+		}
+		filtered := prometheus.NewRegistry()
+		if err := filtered.Register(exp.Filtered(f)); err != nil {
+			logx.Errorf("filtered scrape: %v", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		promhttp.HandlerFor(filtered, promhttp.HandlerOpts{}).ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,176 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+
+	"github.com/conradoqg/statuspage-exporter/internal/collector"
+	"github.com/conradoqg/statuspage-exporter/internal/config"
+	"github.com/conradoqg/statuspage-exporter/internal/events"
+	"github.com/conradoqg/statuspage-exporter/internal/journal"
+)
+
+// testPage is a Statuspage page served by an httptest server; an empty
+// summary makes its fetches fail.
+type testPage struct {
+	name    string
+	labels  string
+	policy  string
+	summary string
+}
+
+// newTestServer starts an exporter over the given pages, waits for their
+// first fetch and serves the exporter's endpoints.
+func newTestServer(t *testing.T, pages ...testPage) (*httptest.Server, *collector.Exporter, *journal.Journal) {
+	t.Helper()
+	var cfg strings.Builder
+	cfg.WriteString("common:\n  interval: 1h\n  timeout: 5s\n  labels:\n    env: test\npages:\n")
+	for _, p := range pages {
+		p := p
+		vendor := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if p.summary == "" || r.URL.Path != "/api/v2/summary.json" {
+				http.Error(w, "unavailable", http.StatusServiceUnavailable)
+				return
+			}
+			fmt.Fprint(w, p.summary)
+		}))
+		t.Cleanup(vendor.Close)
+		fmt.Fprintf(&cfg, "  - name: %s\n    type: statuspage\n    url: %s\n", p.name, vendor.URL)
+		if p.labels != "" {
+			fmt.Fprintf(&cfg, "    labels: {%s}\n", p.labels)
+		}
+		if p.policy != "" {
+			fmt.Fprintf(&cfg, "    unknown_policy: %s\n", p.policy)
+		}
+	}
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(cfg.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	j, err := journal.Open(config.Journal{Retention: 24 * time.Hour, MaxEvents: 1000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bus := events.NewBus(100)
+	j.Run(bus)
+	exp, err := collector.New(c, bus)
+	if err != nil {
+		t.Fatalf("new exporter: %v", err)
+	}
+	select {
+	case <-exp.Ready():
+	case <-time.After(10 * time.Second):
+		t.Fatal("pages were not fetched")
+	}
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(exp)
+	srv := httptest.NewServer(NewMux(reg, exp, j))
+	t.Cleanup(srv.Close)
+	return srv, exp, j
+}
+
+// getJSON decodes the JSON body of a GET request into v and returns the
+// status code.
+func getJSON(t *testing.T, url string, v any) int {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatalf("decode %s: %v", url, err)
+	}
+	return resp.StatusCode
+}
+
+const githubSummary = `{
+  "page": {"updated_at": "2024-05-01T10:00:00Z"},
+  "components": [
+    {"id": "g1", "name": "Core", "status": "operational", "group": true},
+    {"id": "c1", "name": "API Requests", "status": "operational", "group_id": "g1"},
+    {"id": "c2", "name": "Actions", "status": "partial_outage", "group_id": "g1", "description": "CI <runners>"},
+    {"id": "c3", "name": "Pages", "status": "mystery"}
+  ],
+  "incidents": [
+    {"id": "inc1", "name": "Actions <delays>", "status": "investigating", "impact": "major", "shortlink": "https://stspg.io/inc1", "started_at": "2024-05-01T09:00:00Z"}
+  ],
+  "scheduled_maintenances": [
+    {"id": "m1", "name": "Database upgrade", "status": "scheduled", "impact": "maintenance", "scheduled_for": "2099-01-01T00:00:00Z", "scheduled_until": "2099-01-01T02:00:00Z"}
+  ]
+}`
+
+const stripeSummary = `{
+  "components": [
+    {"id": "s1", "name": "Payments", "status": "operational"},
+    {"id": "s2", "name": "Dashboard", "status": "degraded_performance"}
+  ]
+}`
+
+func TestMetricsFilter(t *testing.T) {
+	srv, _, _ := newTestServer(t,
+		testPage{name: "github", summary: githubSummary},
+		testPage{name: "stripe", labels: "team: payments", summary: stripeSummary},
+	)
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{"github", "stripe"}},
+		{"?page=github", []string{"github"}},
+		{"?label.team=payments", []string{"stripe"}},
+		{"?provider=statuspage&label.team=payments", []string{"stripe"}},
+		{"?page=slack", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			resp, err := http.Get(srv.URL + "/metrics" + tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			var p expfmt.TextParser
+			families, err := p.TextToMetricFamilies(resp.Body)
+			if err != nil {
+				t.Fatalf("parse metrics: %v", err)
+			}
+			seen := make(map[string]bool)
+			for _, mf := range families {
+				for _, m := range mf.GetMetric() {
+					for _, l := range m.GetLabel() {
+						if l.GetName() == "page" {
+							seen[l.GetValue()] = true
+						}
+					}
+				}
+			}
+			var got []string
+			for page := range seen {
+				got = append(got, page)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("pages %v, want %v", got, tt.want)
+			}
+			if _, ok := families["statuspage_component_up"]; tt.want != nil && !ok {
+				t.Error("statuspage_component_up missing")
+			}
+		})
+	}
+}
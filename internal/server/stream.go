@@ -0,0 +1,95 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/conradoqg/statuspage-exporter/internal/collector"
+	"github.com/conradoqg/statuspage-exporter/internal/events"
+	"github.com/conradoqg/statuspage-exporter/internal/logx"
+)
+
+// streamKeepalive is how often an idle event stream gets a comment line, so
+// proxies do not close it.
+const streamKeepalive = 15 * time.Second
+
+// streamHandler serves detected changes as Server-Sent Events. Clients resume
+// with the Last-Event-ID header (or ?last_event_id= on first connect) and get
+// the buffered events they missed.
+func streamHandler(exp *collector.Exporter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		f := collector.ParseFilter(r.URL.Query())
+		lastID := r.Header.Get("Last-Event-ID")
+		if lastID == "" {
+			lastID = r.URL.Query().Get("last_event_id")
+		}
+		var after uint64
+		if lastID != "" {
+			var err error
+			if after, err = strconv.ParseUint(lastID, 10, 64); err != nil {
+				writeError(w, http.StatusBadRequest, "invalid Last-Event-ID")
+				return
+			}
+		}
+		rc := http.NewResponseController(w)
+		// The server's write timeout would cut long-lived streams
+		if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+			logx.Debugf("event stream: clear write deadline: %v", err)
+		}
+
+		replay, ch, cancel := exp.Events().Subscribe(after)
+		defer cancel()
+
+		h := w.Header()
+		h.Set("Content-Type", "text/event-stream")
+		h.Set("Cache-Control", "no-cache")
+		h.Set("Connection", "keep-alive")
+		h.Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, "retry: %d\n\n", 5000)
+
+		send := func(ev events.Event) bool {
+			if !exp.MatchEvent(f, ev) {
+				return true
+			}
+			b, err := json.Marshal(ev)
+			if err != nil {
+				logx.Warnf("event stream: encode event %d: %v", ev.ID, err)
+				return true
+			}
+			_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", ev.ID, ev.Type, b)
+			return err == nil
+		}
+		for _, ev := range replay {
+			if !send(ev) {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+
+		ticker := time.NewTicker(streamKeepalive)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case ev := <-ch:
+				if !send(ev) {
+					return
+				}
+			case <-ticker.C:
+				if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+					return
+				}
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/conradoqg/statuspage-exporter/internal/events"
+)
+
+// openStream connects to the event stream and returns once the server has
+// subscribed, i.e. after the retry line.
+func openStream(t *testing.T, url, lastID string) *bufio.Reader {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lastID != "" {
+		req.Header.Set("Last-Event-ID", lastID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status %d", resp.StatusCode)
+	}
+	r := bufio.NewReader(resp.Body)
+	if line, err := r.ReadString('\n'); err != nil || !strings.HasPrefix(line, "retry: ") {
+		t.Fatalf("first line %q: %v", line, err)
+	}
+	return r
+}
+
+// readIDs reads n events from the stream and returns their IDs.
+func readIDs(t *testing.T, r *bufio.Reader, n int) []uint64 {
+	t.Helper()
+	ids := make([]uint64, 0, n)
+	for len(ids) < n {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read stream after %v: %v", ids, err)
+		}
+		if v, ok := strings.CutPrefix(strings.TrimSpace(line), "id: "); ok {
+			id, err := strconv.ParseUint(v, 10, 64)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func TestStreamReplay(t *testing.T) {
+	srv, exp, _ := newTestServer(t,
+		testPage{name: "github", summary: githubSummary},
+		testPage{name: "stripe", labels: "team: payments", summary: stripeSummary},
+	)
+	bus := exp.Events()
+	publish := func(page string, typ events.Type) uint64 {
+		return bus.Publish(events.Event{Type: typ, Provider: "statuspage", Page: page}).ID
+	}
+	first := publish("github", events.ComponentStatusChanged)
+	second := publish("stripe", events.IncidentOpened)
+	third := publish("github", events.IncidentOpened)
+	after := strconv.FormatUint(first, 10)
+
+	tests := []struct {
+		name   string
+		query  string
+		header string
+		want   []uint64
+	}{
+		{"Last-Event-ID header", "", after, []uint64{second, third}},
+		{"last_event_id parameter", "?last_event_id=" + after, "", []uint64{second, third}},
+		{"header wins over the parameter", "?last_event_id=0", strconv.FormatUint(second, 10), []uint64{third}},
+		{"page filter", "?page=github&last_event_id=" + after, "", []uint64{third}},
+		{"label filter", "?label.team=payments&last_event_id=" + after, "", []uint64{second}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := openStream(t, srv.URL+"/api/v1/events/stream"+tt.query, tt.header)
+			if got := readIDs(t, r, len(tt.want)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// Live events go through the same filter as the replay
+	r := openStream(t, srv.URL+"/api/v1/events/stream?page=github&last_event_id="+strconv.FormatUint(third, 10), "")
+	publish("stripe", events.IncidentResolved)
+	live := publish("github", events.IncidentResolved)
+	if got := readIDs(t, r, 1); !reflect.DeepEqual(got, []uint64{live}) {
+		t.Errorf("live events %v, want [%d]", got, live)
+	}
+
+	resp, err := http.Get(srv.URL + "/api/v1/events/stream?last_event_id=abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("invalid ID: status %d, want 400", resp.StatusCode)
+	}
+}
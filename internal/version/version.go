@@ -0,0 +1,27 @@
+package version
+
+import (
+	"runtime"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Build metadata, overridden at build time via -ldflags "-X ...".
+var (
+	Version = "dev"
+	Commit  = "unknown"
+)
+
+// NewCollector returns a collector exposing <program>_build_info with
+// version, commit and Go version labels; value is always 1.
+func NewCollector(program string) prometheus.Collector {
+	return prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: program + "_build_info",
+		Help: "Build information of the exporter; value is 1",
+		ConstLabels: prometheus.Labels{
+			"version":   Version,
+			"commit":    Commit,
+			"goversion": runtime.Version(),
+		},
+	}, func() float64 { return 1 })
+}